@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// Album is a group of photos and videos to send as a single message, from
+// AlbumReply.
+type Album struct {
+	bot    *Bot
+	from   *Message
+	config tgbotapi.MediaGroupConfig
+}
+
+// AlbumPhoto creates a photo for an album, with its own caption. The
+// ParseMode and Spoiler options apply.
+func AlbumPhoto(file tgbotapi.InputFile, caption string, opts ...MediaOption) tgbotapi.InputMedia {
+	info := mediaOptions(opts)
+
+	media := tgbotapi.NewInputMediaPhoto(file)
+	media.Caption = caption
+	media.ParseMode = info.parseMode
+	media.HasSpoiler = info.hasSpoiler
+
+	return media
+}
+
+// AlbumVideo creates a video for an album, with its own caption. The
+// ParseMode, Duration, Dimensions, Thumbnail, Streaming and Spoiler options
+// apply.
+func AlbumVideo(file tgbotapi.InputFile, caption string, opts ...MediaOption) tgbotapi.InputMedia {
+	info := mediaOptions(opts)
+
+	media := tgbotapi.NewInputMediaVideo(file)
+	media.Caption = caption
+	media.ParseMode = info.parseMode
+	media.HasSpoiler = info.hasSpoiler
+	media.Duration = info.duration
+	media.Width = info.width
+	media.Height = info.height
+	media.Thumbnail = info.thumbnail
+	media.SupportsStreaming = info.supportsStreaming
+
+	return media
+}
+
+// AlbumReply sends between 2 and 10 photos and videos, made with AlbumPhoto
+// and AlbumVideo, as an album in reply to this message. Reply markup isn't
+// sent, as albums can't have any.
+func (msg *Message) AlbumReply(media ...tgbotapi.InputMedia) *Album {
+	config := tgbotapi.NewMediaGroup(msg.chatRef(), media...)
+	config.SendOptions = msg.replyOptions()
+	config.ReplyMarkup = nil
+
+	return &Album{
+		bot:    msg.bot,
+		from:   msg,
+		config: config,
+	}
+}
+
+// Album sends between 2 and 10 photos and videos as an album to the
+// specified chat.
+func (bot *Bot) Album(chat tgbotapi.ChatRef, media ...tgbotapi.InputMedia) *Album {
+	return bot.Message(chat, "").AlbumReply(media...)
+}
+
+// OnProgress has fn called as the album's files are uploaded, which happens
+// in a single request.
+func (album *Album) OnProgress(fn tgbotapi.ProgressFunc) *Album {
+	album.config.Progress = fn
+
+	return album
+}
+
+// Send sends the album.
+func (album *Album) Send() error {
+	_, err := album.SendAll()
+
+	return err
+}
+
+// SendAll sends the album, and returns the message each photo and video was
+// sent as.
+func (album *Album) SendAll() ([]Message, error) {
+	sent, err := album.bot.api.SendMediaGroup(album.config)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, len(sent))
+	for i, message := range sent {
+		album.from.scheduleDeletion(message)
+		messages[i] = album.bot.sent(message)
+	}
+
+	return messages, nil
+}
@@ -7,6 +7,14 @@ type BotAPI struct {
 	Debug   bool        `json:"debug"`
 	Self    User        `json:"-"`
 	Updates chan Update `json:"-"`
+
+	// UploadRetries is how many times an upload which failed before it was
+	// sent in full is retried.
+	UploadRetries int `json:"upload_retries"`
+
+	// UploadCache, if set, is used to send files which were uploaded
+	// before by their FileID.
+	UploadCache *UploadCache `json:"-"`
 }
 
 // NewBotAPI creates a new BotAPI instance.
@@ -14,6 +22,8 @@ type BotAPI struct {
 func NewBotAPI(token string) (*BotAPI, error) {
 	bot := &BotAPI{
 		Token: token,
+
+		UploadRetries: 2,
 	}
 
 	self, err := bot.GetMe()
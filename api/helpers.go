@@ -8,12 +8,10 @@ import (
 // Perhaps set a ChatAction of ChatTyping while processing.
 //
 // chatID is where to send it, text is the message text.
-func NewMessage(chatID int32, text string) MessageConfig {
+func NewMessage(chatID ChatRef, text string) MessageConfig {
 	return MessageConfig{
 		ChatID: chatID,
 		Text:   text,
-		DisableWebPagePreview: false,
-		ReplyToMessageID:      0,
 	}
 }
 
@@ -21,7 +19,7 @@ func NewMessage(chatID int32, text string) MessageConfig {
 //
 // chatID is where to send it, fromChatID is the source chat,
 // and messageID is the ID of the original message.
-func NewForward(chatID int32, fromChatID int32, messageID int32) ForwardConfig {
+func NewForward(chatID ChatRef, fromChatID ChatRef, messageID int32) ForwardConfig {
 	return ForwardConfig{
 		ChatID:     chatID,
 		FromChatID: fromChatID,
@@ -29,28 +27,42 @@ func NewForward(chatID int32, fromChatID int32, messageID int32) ForwardConfig {
 	}
 }
 
+// NewPhoto sends a photo, which may be uploaded or already on Telegram's servers.
+// Perhaps set a ChatAction of ChatUploadPhoto while processing.
+//
+// chatID is where to send it, file is the photo to send.
+func NewPhoto(chatID ChatRef, file InputFile) PhotoConfig {
+	return PhotoConfig{
+		ChatID: chatID,
+		File:   file,
+	}
+}
+
 // NewPhotoUpload creates a new photo uploader.
 // This requires a file on the local filesystem to upload to Telegram.
 // Perhaps set a ChatAction of ChatUploadPhoto while processing.
 //
 // chatID is where to send it, filename is the path to the file.
-func NewPhotoUpload(chatID int32, filename string) PhotoConfig {
-	return PhotoConfig{
-		ChatID:           chatID,
-		UseExistingPhoto: false,
-		FilePath:         filename,
-	}
+func NewPhotoUpload(chatID ChatRef, filename string) PhotoConfig {
+	return NewPhoto(chatID, FilePath(filename))
 }
 
 // NewPhotoShare shares an existing photo.
 // You may use this to reshare an existing photo without reuploading it.
 //
 // chatID is where to send it, fileID is the ID of the file already uploaded.
-func NewPhotoShare(chatID int32, fileID string) PhotoConfig {
-	return PhotoConfig{
-		ChatID:           chatID,
-		UseExistingPhoto: true,
-		FileID:           fileID,
+func NewPhotoShare(chatID ChatRef, fileID string) PhotoConfig {
+	return NewPhoto(chatID, FileID(fileID))
+}
+
+// NewAudio sends an audio clip, which may be uploaded or already on Telegram's servers.
+// Perhaps set a ChatAction of ChatUploadAudio while processing.
+//
+// chatID is where to send it, file is the audio clip to send.
+func NewAudio(chatID ChatRef, file InputFile) AudioConfig {
+	return AudioConfig{
+		ChatID: chatID,
+		File:   file,
 	}
 }
 
@@ -59,23 +71,26 @@ func NewPhotoShare(chatID int32, fileID string) PhotoConfig {
 // Perhaps set a ChatAction of ChatRecordAudio or ChatUploadAudio while processing.
 //
 // chatID is where to send it, filename is the path to the file.
-func NewAudioUpload(chatID int32, filename string) AudioConfig {
-	return AudioConfig{
-		ChatID:           chatID,
-		UseExistingAudio: false,
-		FilePath:         filename,
-	}
+func NewAudioUpload(chatID ChatRef, filename string) AudioConfig {
+	return NewAudio(chatID, FilePath(filename))
 }
 
 // NewAudioShare shares an existing audio file.
 // You may use this to reshare an existing audio file without reuploading it.
 //
 // chatID is where to send it, fileID is the ID of the audio already uploaded.
-func NewAudioShare(chatID int32, fileID string) AudioConfig {
-	return AudioConfig{
-		ChatID:           chatID,
-		UseExistingAudio: true,
-		FileID:           fileID,
+func NewAudioShare(chatID ChatRef, fileID string) AudioConfig {
+	return NewAudio(chatID, FileID(fileID))
+}
+
+// NewDocument sends a document, which may be uploaded or already on Telegram's servers.
+// Perhaps set a ChatAction of ChatUploadDocument while processing.
+//
+// chatID is where to send it, file is the document to send.
+func NewDocument(chatID ChatRef, file InputFile) DocumentConfig {
+	return DocumentConfig{
+		ChatID: chatID,
+		File:   file,
 	}
 }
 
@@ -84,23 +99,25 @@ func NewAudioShare(chatID int32, fileID string) AudioConfig {
 // Perhaps set a ChatAction of ChatUploadDocument while processing.
 //
 // chatID is where to send it, filename is the path to the file.
-func NewDocumentUpload(chatID int32, filename string) DocumentConfig {
-	return DocumentConfig{
-		ChatID:              chatID,
-		UseExistingDocument: false,
-		FilePath:            filename,
-	}
+func NewDocumentUpload(chatID ChatRef, filename string) DocumentConfig {
+	return NewDocument(chatID, FilePath(filename))
 }
 
 // NewDocumentShare shares an existing document.
 // You may use this to reshare an existing document without reuploading it.
 //
 // chatID is where to send it, fileID is the ID of the document already uploaded.
-func NewDocumentShare(chatID int32, fileID string) DocumentConfig {
-	return DocumentConfig{
-		ChatID:              chatID,
-		UseExistingDocument: true,
-		FileID:              fileID,
+func NewDocumentShare(chatID ChatRef, fileID string) DocumentConfig {
+	return NewDocument(chatID, FileID(fileID))
+}
+
+// NewSticker sends a sticker, which may be uploaded or already on Telegram's servers.
+//
+// chatID is where to send it, file is the sticker to send.
+func NewSticker(chatID ChatRef, file InputFile) StickerConfig {
+	return StickerConfig{
+		ChatID: chatID,
+		File:   file,
 	}
 }
 
@@ -108,23 +125,26 @@ func NewDocumentShare(chatID int32, fileID string) DocumentConfig {
 // This requires a file on the local filesystem to upload to Telegram.
 //
 // chatID is where to send it, filename is the path to the file.
-func NewStickerUpload(chatID int32, filename string) StickerConfig {
-	return StickerConfig{
-		ChatID:             chatID,
-		UseExistingSticker: false,
-		FilePath:           filename,
-	}
+func NewStickerUpload(chatID ChatRef, filename string) StickerConfig {
+	return NewSticker(chatID, FilePath(filename))
 }
 
 // NewStickerShare shares an existing sticker.
 // You may use this to reshare an existing sticker without reuploading it.
 //
 // chatID is where to send it, fileID is the ID of the sticker already uploaded.
-func NewStickerShare(chatID int32, fileID string) StickerConfig {
-	return StickerConfig{
-		ChatID:             chatID,
-		UseExistingSticker: true,
-		FileID:             fileID,
+func NewStickerShare(chatID ChatRef, fileID string) StickerConfig {
+	return NewSticker(chatID, FileID(fileID))
+}
+
+// NewVideo sends a video, which may be uploaded or already on Telegram's servers.
+// Perhaps set a ChatAction of ChatUploadVideo while processing.
+//
+// chatID is where to send it, file is the video to send.
+func NewVideo(chatID ChatRef, file InputFile) VideoConfig {
+	return VideoConfig{
+		ChatID: chatID,
+		File:   file,
 	}
 }
 
@@ -133,23 +153,200 @@ func NewStickerShare(chatID int32, fileID string) StickerConfig {
 // Perhaps set a ChatAction of ChatRecordVideo or ChatUploadVideo while processing.
 //
 // chatID is where to send it, filename is the path to the file.
-func NewVideoUpload(chatID int32, filename string) VideoConfig {
-	return VideoConfig{
-		ChatID:           chatID,
-		UseExistingVideo: false,
-		FilePath:         filename,
-	}
+func NewVideoUpload(chatID ChatRef, filename string) VideoConfig {
+	return NewVideo(chatID, FilePath(filename))
 }
 
 // NewVideoShare shares an existing video.
 // You may use this to reshare an existing video without reuploading it.
 //
 // chatID is where to send it, fileID is the ID of the video already uploaded.
-func NewVideoShare(chatID int32, fileID string) VideoConfig {
-	return VideoConfig{
-		ChatID:           chatID,
-		UseExistingVideo: true,
-		FileID:           fileID,
+func NewVideoShare(chatID ChatRef, fileID string) VideoConfig {
+	return NewVideo(chatID, FileID(fileID))
+}
+
+// NewVoice sends a voice message, which may be uploaded or already on Telegram's servers.
+// Perhaps set a ChatAction of ChatRecordVoice or ChatUploadVoice while processing.
+//
+// chatID is where to send it, file is the voice message to send.
+func NewVoice(chatID ChatRef, file InputFile) VoiceConfig {
+	return VoiceConfig{
+		ChatID: chatID,
+		File:   file,
+	}
+}
+
+// NewVoiceUpload creates a new voice message uploader.
+// This requires a file on the local filesystem to upload to Telegram.
+// Perhaps set a ChatAction of ChatRecordVoice or ChatUploadVoice while processing.
+//
+// chatID is where to send it, filename is the path to the file.
+func NewVoiceUpload(chatID ChatRef, filename string) VoiceConfig {
+	return NewVoice(chatID, FilePath(filename))
+}
+
+// NewVoiceShare shares an existing voice message.
+// You may use this to reshare an existing voice message without reuploading it.
+//
+// chatID is where to send it, fileID is the ID of the voice message already uploaded.
+func NewVoiceShare(chatID ChatRef, fileID string) VoiceConfig {
+	return NewVoice(chatID, FileID(fileID))
+}
+
+// NewAnimation sends an animation, which may be uploaded or already on Telegram's servers.
+//
+// chatID is where to send it, file is the GIF or silent video to send.
+func NewAnimation(chatID ChatRef, file InputFile) AnimationConfig {
+	return AnimationConfig{
+		ChatID: chatID,
+		File:   file,
+	}
+}
+
+// NewAnimationUpload creates a new animation uploader.
+// This requires a file on the local filesystem to upload to Telegram.
+//
+// chatID is where to send it, filename is the path to the file.
+func NewAnimationUpload(chatID ChatRef, filename string) AnimationConfig {
+	return NewAnimation(chatID, FilePath(filename))
+}
+
+// NewAnimationShare shares an existing animation.
+// You may use this to reshare an existing animation without reuploading it.
+//
+// chatID is where to send it, fileID is the ID of the animation already uploaded.
+func NewAnimationShare(chatID ChatRef, fileID string) AnimationConfig {
+	return NewAnimation(chatID, FileID(fileID))
+}
+
+// NewVideoNote sends a video note, which may be uploaded or already on Telegram's servers.
+// Perhaps set a ChatAction of ChatRecordVideoNote or ChatUploadVideoNote while processing.
+//
+// chatID is where to send it, file is the video note to send.
+func NewVideoNote(chatID ChatRef, file InputFile) VideoNoteConfig {
+	return VideoNoteConfig{
+		ChatID: chatID,
+		File:   file,
+	}
+}
+
+// NewVideoNoteUpload creates a new video note uploader.
+// This requires a file on the local filesystem to upload to Telegram.
+// Perhaps set a ChatAction of ChatRecordVideoNote or ChatUploadVideoNote while processing.
+//
+// chatID is where to send it, filename is the path to the file.
+func NewVideoNoteUpload(chatID ChatRef, filename string) VideoNoteConfig {
+	return NewVideoNote(chatID, FilePath(filename))
+}
+
+// NewVideoNoteShare shares an existing video note.
+// You may use this to reshare an existing video note without reuploading it.
+//
+// chatID is where to send it, fileID is the ID of the video note already uploaded.
+func NewVideoNoteShare(chatID ChatRef, fileID string) VideoNoteConfig {
+	return NewVideoNote(chatID, FileID(fileID))
+}
+
+// NewMediaGroup sends photos and videos as an album.
+//
+// chatID is where to send it, media are the 2 to 10 items in it.
+func NewMediaGroup(chatID ChatRef, media ...InputMedia) MediaGroupConfig {
+	return MediaGroupConfig{
+		ChatID: chatID,
+		Media:  media,
+	}
+}
+
+// NewInputMediaPhoto creates a photo for a media group.
+//
+// file is the photo to send.
+func NewInputMediaPhoto(file InputFile) InputMedia {
+	return InputMedia{
+		Type:  "photo",
+		Media: file,
+	}
+}
+
+// NewInputMediaVideo creates a video for a media group.
+//
+// file is the video to send.
+func NewInputMediaVideo(file InputFile) InputMedia {
+	return InputMedia{
+		Type:  "video",
+		Media: file,
+	}
+}
+
+// NewEditMessageText changes the text of a message.
+//
+// chatID and messageID identify the message, text is its new text.
+func NewEditMessageText(chatID ChatRef, messageID int32, text string) EditMessageTextConfig {
+	return EditMessageTextConfig{
+		EditTarget: EditTarget{
+			ChatID:    chatID,
+			MessageID: messageID,
+		},
+		Text: text,
+	}
+}
+
+// NewEditMessageCaption changes the caption of a message.
+//
+// chatID and messageID identify the message, caption is its new caption.
+func NewEditMessageCaption(chatID ChatRef, messageID int32, caption string) EditMessageCaptionConfig {
+	return EditMessageCaptionConfig{
+		EditTarget: EditTarget{
+			ChatID:    chatID,
+			MessageID: messageID,
+		},
+		Caption: caption,
+	}
+}
+
+// NewEditMessageMedia replaces the media of a message.
+//
+// chatID and messageID identify the message, media is its new media.
+func NewEditMessageMedia(chatID ChatRef, messageID int32, media InputMedia) EditMessageMediaConfig {
+	return EditMessageMediaConfig{
+		EditTarget: EditTarget{
+			ChatID:    chatID,
+			MessageID: messageID,
+		},
+		Media: media,
+	}
+}
+
+// NewEditMessageReplyMarkup changes the inline keyboard of a message.
+//
+// chatID and messageID identify the message, markup is its new keyboard,
+// or nil to remove it.
+func NewEditMessageReplyMarkup(chatID ChatRef, messageID int32, markup *InlineKeyboardMarkup) EditMessageReplyMarkupConfig {
+	return EditMessageReplyMarkupConfig{
+		EditTarget: EditTarget{
+			ChatID:    chatID,
+			MessageID: messageID,
+		},
+		ReplyMarkup: markup,
+	}
+}
+
+// NewDeleteMessage deletes a message.
+//
+// chatID and messageID identify the message.
+func NewDeleteMessage(chatID ChatRef, messageID int32) DeleteMessageConfig {
+	return DeleteMessageConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+}
+
+// NewDeleteMessages deletes several messages from one chat.
+//
+// chatID is the chat, messageIDs are the messages in it to delete.
+func NewDeleteMessages(chatID ChatRef, messageIDs ...int32) DeleteMessagesConfig {
+	return DeleteMessagesConfig{
+		ChatID:     chatID,
+		MessageIDs: messageIDs,
 	}
 }
 
@@ -157,13 +354,37 @@ func NewVideoShare(chatID int32, fileID string) VideoConfig {
 // Perhaps set a ChatAction of ChatFindLocation while processing.
 //
 // chatID is where to send it, latitude and longitude are coordinates.
-func NewLocation(chatID int32, latitude float64, longitude float64) LocationConfig {
+func NewLocation(chatID ChatRef, latitude float64, longitude float64) LocationConfig {
 	return LocationConfig{
-		ChatID:           chatID,
-		Latitude:         latitude,
-		Longitude:        longitude,
-		ReplyToMessageID: 0,
-		ReplyMarkup:      nil,
+		ChatID:    chatID,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+}
+
+// NewContact shares a phone contact.
+//
+// chatID is where to send it, phoneNumber and firstName describe the contact.
+func NewContact(chatID ChatRef, phoneNumber string, firstName string) ContactConfig {
+	return ContactConfig{
+		ChatID:      chatID,
+		PhoneNumber: phoneNumber,
+		FirstName:   firstName,
+	}
+}
+
+// NewVenue shares a venue.
+// Perhaps set a ChatAction of ChatFindLocation while processing.
+//
+// chatID is where to send it, latitude and longitude are coordinates,
+// title and address describe the venue.
+func NewVenue(chatID ChatRef, latitude float64, longitude float64, title string, address string) VenueConfig {
+	return VenueConfig{
+		ChatID:    chatID,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Title:     title,
+		Address:   address,
 	}
 }
 
@@ -171,17 +392,39 @@ func NewLocation(chatID int32, latitude float64, longitude float64) LocationConf
 // Actions last for 5 seconds, or until your next action.
 //
 // chatID is where to send it, action should be set via CHAT constants.
-func NewChatAction(chatID int32, action ChatAction) ChatActionConfig {
+func NewChatAction(chatID ChatRef, action ChatAction) ChatActionConfig {
 	return ChatActionConfig{
 		ChatID: chatID,
 		Action: action,
 	}
 }
 
+// NewCallback answers a callback query with a notification.
+//
+// queryID is the ID of the query, text is the notification, or empty for none.
+func NewCallback(queryID, text string) CallbackConfig {
+	return CallbackConfig{
+		CallbackQueryID: queryID,
+		Text:            text,
+	}
+}
+
+// NewCallbackAlert answers a callback query with an alert the user must
+// dismiss.
+//
+// queryID is the ID of the query, text is the alert.
+func NewCallbackAlert(queryID, text string) CallbackConfig {
+	return CallbackConfig{
+		CallbackQueryID: queryID,
+		Text:            text,
+		ShowAlert:       true,
+	}
+}
+
 // NewUserProfilePhotos gets user profile photos.
 //
 // userID is the ID of the user you wish to get profile photos from.
-func NewUserProfilePhotos(userID int32) UserProfilePhotosConfig {
+func NewUserProfilePhotos(userID int64) UserProfilePhotosConfig {
 	return UserProfilePhotosConfig{
 		UserID: userID,
 		Offset: 0,
@@ -189,6 +432,15 @@ func NewUserProfilePhotos(userID int32) UserProfilePhotosConfig {
 	}
 }
 
+// NewFile gets the information needed to download a file.
+//
+// fileID is the ID of the file to download.
+func NewFile(fileID string) FileConfig {
+	return FileConfig{
+		FileID: fileID,
+	}
+}
+
 // NewUpdate gets updates since the last Offset.
 //
 // offset is the last Update ID to include.
@@ -0,0 +1,203 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotRewindable is returned when an upload fails part way through, and
+// can't be retried because one of its files is read from a reader which
+// can't seek back to where it started.
+var ErrNotRewindable = errors.New("tgbotapi: cannot retry upload from a reader which is not seekable")
+
+// ProgressFunc is called as files are uploaded, with the number of bytes of
+// them sent so far and their total size, or -1 if that isn't known. It is
+// called from the goroutine streaming the upload, so should return quickly.
+type ProgressFunc func(sent, total int64)
+
+// InputFile is a file to send with a request. It can be a file on the local
+// filesystem, the contents of a reader or byte slice, a URL for Telegram to
+// fetch, or the FileID of a file already on Telegram's servers.
+type InputFile struct {
+	path   string
+	name   string
+	reader io.Reader
+	data   []byte
+	url    string
+	fileID string
+}
+
+// FilePath uploads the file at path on the local filesystem.
+func FilePath(path string) InputFile {
+	return InputFile{
+		path: path,
+		name: filepath.Base(path),
+	}
+}
+
+// FileReader uploads the contents of r, calling the file name.
+func FileReader(name string, r io.Reader) InputFile {
+	return InputFile{
+		name:   name,
+		reader: r,
+	}
+}
+
+// FileBytes uploads data, calling the file name.
+func FileBytes(name string, data []byte) InputFile {
+	return InputFile{
+		name: name,
+		data: data,
+	}
+}
+
+// FileURL has Telegram fetch the file from url.
+func FileURL(url string) InputFile {
+	return InputFile{
+		url: url,
+	}
+}
+
+// FileID resends a file which is already on Telegram's servers.
+func FileID(fileID string) InputFile {
+	return InputFile{
+		fileID: fileID,
+	}
+}
+
+// IsZero returns true if no file has been set.
+func (file InputFile) IsZero() bool {
+	return file.path == "" && file.reader == nil && file.data == nil &&
+		file.url == "" && file.fileID == ""
+}
+
+// NeedsUpload returns true if the file's contents have to be sent with the
+// request, rather than a reference to them.
+func (file InputFile) NeedsUpload() bool {
+	return file.path != "" || file.reader != nil || file.data != nil
+}
+
+// Name returns the file name the file is uploaded as.
+func (file InputFile) Name() string {
+	return file.name
+}
+
+// value returns the request parameter for a file that needs no upload.
+func (file InputFile) value() string {
+	if file.fileID != "" {
+		return file.fileID
+	}
+
+	return file.url
+}
+
+// open returns the contents of a file that needs uploading.
+func (file InputFile) open() (io.ReadCloser, error) {
+	switch {
+	case file.path != "":
+		return os.Open(file.path)
+	case file.reader != nil:
+		return ioutil.NopCloser(file.reader), nil
+	case file.data != nil:
+		return ioutil.NopCloser(bytes.NewReader(file.data)), nil
+	}
+
+	return nil, errors.New("tgbotapi: file has no contents to upload")
+}
+
+// size returns the number of bytes a file needs to upload, or -1 if that
+// can't be told without reading it.
+func (file InputFile) size(offset int64) int64 {
+	switch {
+	case file.path != "":
+		info, err := os.Stat(file.path)
+		if err != nil {
+			return -1
+		}
+
+		return info.Size()
+	case file.data != nil:
+		return int64(len(file.data))
+	}
+
+	if r, ok := file.reader.(interface {
+		Len() int
+	}); ok {
+		return int64(r.Len())
+	}
+
+	if seeker, ok := file.reader.(io.Seeker); ok {
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return -1
+		}
+
+		return end - offset
+	}
+
+	return -1
+}
+
+// progressReader reports each read from a file being uploaded.
+type progressReader struct {
+	io.ReadCloser
+
+	report func(n int)
+}
+
+func (r progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.report(n)
+	}
+
+	return n, err
+}
+
+// offset returns the position a seekable reader starts at, so it can be
+// re-read from there if an upload is retried.
+func (file InputFile) offset() (int64, error) {
+	if seeker, ok := file.reader.(io.Seeker); ok {
+		return seeker.Seek(0, io.SeekCurrent)
+	}
+
+	return 0, nil
+}
+
+// rewindable returns true if the file can be read again after an upload of it
+// has been attempted.
+func (file InputFile) rewindable() bool {
+	if file.reader == nil {
+		return true
+	}
+
+	_, ok := file.reader.(io.Seeker)
+	return ok
+}
+
+// reopen returns the contents of a file again for another attempt at
+// uploading it, with seekable readers going back to offset.
+func (file InputFile) reopen(offset int64) (io.ReadCloser, error) {
+	if file.reader == nil {
+		return file.open()
+	}
+
+	seeker, ok := file.reader.(io.Seeker)
+	if !ok {
+		return nil, ErrNotRewindable
+	}
+
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(file.reader), nil
+}
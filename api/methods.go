@@ -1,122 +1,342 @@
 package tgbotapi
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
-	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // Constant values for ChatActions
 type ChatAction string
 
 const (
-	ChatTyping         ChatAction = "typing"
-	ChatUploadPhoto               = "upload_photo"
-	ChatRecordVideo               = "record_video"
-	ChatUploadVideo               = "upload_video"
-	ChatRecordAudio               = "record_audio"
-	ChatUploadAudio               = "upload_audio"
-	ChatUploadDocument            = "upload_document"
-	ChatFindLocation              = "find_location"
+	ChatTyping          ChatAction = "typing"
+	ChatUploadPhoto     ChatAction = "upload_photo"
+	ChatRecordVideo     ChatAction = "record_video"
+	ChatUploadVideo     ChatAction = "upload_video"
+	ChatRecordAudio     ChatAction = "record_audio"
+	ChatUploadAudio     ChatAction = "upload_audio"
+	ChatRecordVoice     ChatAction = "record_voice"
+	ChatUploadVoice     ChatAction = "upload_voice"
+	ChatUploadDocument  ChatAction = "upload_document"
+	ChatFindLocation    ChatAction = "find_location"
+	ChatRecordVideoNote ChatAction = "record_video_note"
+	ChatUploadVideoNote ChatAction = "upload_video_note"
 )
 
+// SendOptions contains the options shared by every request which sends a
+// message. It is embedded in each of the *Config types for those requests.
+type SendOptions struct {
+	// MessageThreadID sends the message to a forum topic.
+	MessageThreadID int32
+
+	// ReplyToMessageID makes the message a reply to another in the same chat.
+	ReplyToMessageID int32
+
+	// AllowSendingWithoutReply sends the message even if the message it
+	// replies to has since been deleted.
+	AllowSendingWithoutReply bool
+
+	// ReplyParameters replies to, and optionally quotes, another message.
+	// If set, it takes precedence over ReplyToMessageID.
+	ReplyParameters *ReplyParameters
+
+	// DisableNotification sends the message silently.
+	DisableNotification bool
+
+	// ProtectContent prevents the message from being forwarded or saved.
+	ProtectContent bool
+
+	// LinkPreviewOptions controls the link preview of text messages.
+	LinkPreviewOptions *LinkPreviewOptions
+
+	// ReplyMarkup attaches a keyboard to the message, or removes one.
+	ReplyMarkup ReplyMarkup
+}
+
 // MessageConfig contains information about a SendMessage request.
 type MessageConfig struct {
-	ChatID                int32
+	SendOptions
+
+	ChatID                ChatRef
 	Text                  string
 	DisableWebPagePreview bool
-	ReplyToMessageID      int32
-	ReplyMarkup           interface{}
 }
 
 // ForwardConfig contains infomation about a ForwardMessage request.
 type ForwardConfig struct {
-	ChatID     int32
-	FromChatID int32
-	MessageID  int32
+	ChatID              ChatRef
+	FromChatID          ChatRef
+	MessageID           int32
+	MessageThreadID     int32
+	DisableNotification bool
+	ProtectContent      bool
 }
 
 // PhotoConfig contains information about a SendPhoto request.
 type PhotoConfig struct {
-	ChatID           int32
-	Caption          string
-	ReplyToMessageID int32
-	ReplyMarkup      interface{}
-	UseExistingPhoto bool
-	FilePath         string
-	FileID           string
+	SendOptions
+
+	ChatID     ChatRef
+	Caption    string
+	ParseMode  string
+	HasSpoiler bool
+	File       InputFile
+	Progress   ProgressFunc
 }
 
 // AudioConfig contains information about a SendAudio request.
 type AudioConfig struct {
-	ChatID           int32
-	ReplyToMessageID int32
-	ReplyMarkup      interface{}
-	UseExistingAudio bool
-	FilePath         string
-	FileID           string
+	SendOptions
+
+	ChatID    ChatRef
+	Caption   string
+	ParseMode string
+	Duration  int32
+	Performer string
+	Title     string
+	Thumbnail InputFile
+	File      InputFile
+	Progress  ProgressFunc
 }
 
 // DocumentConfig contains information about a SendDocument request.
 type DocumentConfig struct {
-	ChatID              int32
-	ReplyToMessageID    int32
-	ReplyMarkup         interface{}
-	UseExistingDocument bool
-	FilePath            string
-	FileID              string
+	SendOptions
+
+	ChatID                      ChatRef
+	Caption                     string
+	ParseMode                   string
+	Thumbnail                   InputFile
+	DisableContentTypeDetection bool
+	File                        InputFile
+	Progress                    ProgressFunc
 }
 
 // StickerConfig contains information about a SendSticker request.
 type StickerConfig struct {
-	ChatID             int32
-	ReplyToMessageID   int32
-	ReplyMarkup        interface{}
-	UseExistingSticker bool
-	FilePath           string
-	FileID             string
+	SendOptions
+
+	ChatID   ChatRef
+	File     InputFile
+	Progress ProgressFunc
 }
 
 // VideoConfig contains information about a SendVideo request.
 type VideoConfig struct {
-	ChatID           int32
-	ReplyToMessageID int32
-	ReplyMarkup      interface{}
-	UseExistingVideo bool
-	FilePath         string
-	FileID           string
+	SendOptions
+
+	ChatID            ChatRef
+	Caption           string
+	ParseMode         string
+	Duration          int32
+	Width             int32
+	Height            int32
+	Thumbnail         InputFile
+	SupportsStreaming bool
+	HasSpoiler        bool
+	File              InputFile
+	Progress          ProgressFunc
+}
+
+// VoiceConfig contains information about a SendVoice request.
+type VoiceConfig struct {
+	SendOptions
+
+	ChatID    ChatRef
+	Caption   string
+	ParseMode string
+	Duration  int32
+	File      InputFile
+	Progress  ProgressFunc
+}
+
+// AnimationConfig contains information about a SendAnimation request.
+type AnimationConfig struct {
+	SendOptions
+
+	ChatID     ChatRef
+	Caption    string
+	ParseMode  string
+	Duration   int32
+	Width      int32
+	Height     int32
+	Thumbnail  InputFile
+	HasSpoiler bool
+	File       InputFile
+	Progress   ProgressFunc
+}
+
+// VideoNoteConfig contains information about a SendVideoNote request.
+type VideoNoteConfig struct {
+	SendOptions
+
+	ChatID    ChatRef
+	Duration  int32
+	Length    int32
+	Thumbnail InputFile
+	File      InputFile
+	Progress  ProgressFunc
+}
+
+// InputMedia is a photo or video to send as part of a media group, or the
+// new media of a message being edited.
+type InputMedia struct {
+	// Type is "photo" or "video", or for EditMessageMedia, also
+	// "animation", "audio" or "document".
+	Type string
+
+	Media           InputFile
+	Caption         string
+	ParseMode       string
+	CaptionEntities []MessageEntity
+	HasSpoiler      bool
+
+	// These only apply to videos and animations.
+	Thumbnail         InputFile
+	Width             int32
+	Height            int32
+	Duration          int32
+	SupportsStreaming bool
+}
+
+// MediaGroupConfig contains information about a SendMediaGroup request.
+type MediaGroupConfig struct {
+	SendOptions
+
+	ChatID   ChatRef
+	Media    []InputMedia
+	Progress ProgressFunc
+}
+
+// EditTarget identifies the message to edit: either ChatID and MessageID,
+// or InlineMessageID for a message sent through inline mode.
+type EditTarget struct {
+	ChatID          ChatRef
+	MessageID       int32
+	InlineMessageID string
+}
+
+// EditMessageTextConfig contains information about an EditMessageText request.
+type EditMessageTextConfig struct {
+	EditTarget
+
+	Text               string
+	ParseMode          string
+	Entities           []MessageEntity
+	LinkPreviewOptions *LinkPreviewOptions
+	ReplyMarkup        *InlineKeyboardMarkup
+}
+
+// EditMessageCaptionConfig contains information about an EditMessageCaption request.
+type EditMessageCaptionConfig struct {
+	EditTarget
+
+	Caption         string
+	ParseMode       string
+	CaptionEntities []MessageEntity
+	ReplyMarkup     *InlineKeyboardMarkup
+}
+
+// EditMessageMediaConfig contains information about an EditMessageMedia request.
+type EditMessageMediaConfig struct {
+	EditTarget
+
+	Media       InputMedia
+	ReplyMarkup *InlineKeyboardMarkup
+	Progress    ProgressFunc
+}
+
+// EditMessageReplyMarkupConfig contains information about an
+// EditMessageReplyMarkup request.
+type EditMessageReplyMarkupConfig struct {
+	EditTarget
+
+	ReplyMarkup *InlineKeyboardMarkup
+}
+
+// DeleteMessageConfig contains information about a DeleteMessage request.
+type DeleteMessageConfig struct {
+	ChatID    ChatRef
+	MessageID int32
+}
+
+// DeleteMessagesConfig contains information about a DeleteMessages request.
+type DeleteMessagesConfig struct {
+	ChatID     ChatRef
+	MessageIDs []int32
 }
 
 // LocationConfig contains information about a SendLocation request.
 type LocationConfig struct {
-	ChatID           int32
-	Latitude         float64
-	Longitude        float64
-	ReplyToMessageID int32
-	ReplyMarkup      interface{}
+	SendOptions
+
+	ChatID    ChatRef
+	Latitude  float64
+	Longitude float64
+}
+
+// ContactConfig contains information about a SendContact request.
+type ContactConfig struct {
+	SendOptions
+
+	ChatID      ChatRef
+	PhoneNumber string
+	FirstName   string
+	LastName    string
+}
+
+// VenueConfig contains information about a SendVenue request.
+type VenueConfig struct {
+	SendOptions
+
+	ChatID       ChatRef
+	Latitude     float64
+	Longitude    float64
+	Title        string
+	Address      string
+	FoursquareID string
 }
 
 // ChatActionConfig contains information about a SendChatAction request.
 type ChatActionConfig struct {
-	ChatID int32
+	ChatID ChatRef
 	Action ChatAction
 }
 
+// CallbackConfig contains information about an AnswerCallbackQuery request.
+type CallbackConfig struct {
+	CallbackQueryID string
+	Text            string
+	ShowAlert       bool
+	URL             string
+	CacheTime       int32
+}
+
 // UserProfilePhotosConfig contains information about a GetUserProfilePhotos request.
 type UserProfilePhotosConfig struct {
-	UserID int32
+	UserID int64
 	Offset int32
 	Limit  int32
 }
 
+// FileConfig contains information about a GetFile request.
+type FileConfig struct {
+	FileID string
+}
+
 // UpdateConfig contains information about a GetUpdates request.
 type UpdateConfig struct {
 	Offset  int32
@@ -133,7 +353,18 @@ type WebhookConfig struct {
 // MakeRequest makes a request to a specific endpoint32 with our token.
 // All requests are POSTs because Telegram doesn't care, and it's easier.
 func (bot *BotAPI) MakeRequest(endpoint32 string, params url.Values) (APIResponse, error) {
-	resp, err := http.PostForm("https://api.telegram.org/bot"+bot.Token+"/"+endpoint32, params)
+	return bot.MakeRequestContext(context.Background(), endpoint32, params)
+}
+
+// MakeRequestContext is MakeRequest, which gives up when ctx is done.
+func (bot *BotAPI) MakeRequestContext(ctx context.Context, endpoint32 string, params url.Values) (APIResponse, error) {
+	req, err := http.NewRequest("POST", "https://api.telegram.org/bot"+bot.Token+"/"+endpoint32, strings.NewReader(params.Encode()))
+	if err != nil {
+		return APIResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return APIResponse{}, err
 	} else {
@@ -153,7 +384,7 @@ func (bot *BotAPI) MakeRequest(endpoint32 string, params url.Values) (APIRespons
 	json.Unmarshal(bytes, &apiResp)
 
 	if !apiResp.Ok {
-		return APIResponse{}, errors.New(apiResp.Description)
+		return APIResponse{}, apiResp.newError()
 	}
 
 	return apiResp, nil
@@ -163,47 +394,127 @@ func (bot *BotAPI) MakeRequest(endpoint32 string, params url.Values) (APIRespons
 //
 // Requires the parameter to hold the file not be in the params.
 func (bot *BotAPI) UploadFile(endpoint32 string, params map[string]string, fieldname string, filename string) (APIResponse, error) {
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
+	return bot.UploadFiles(endpoint32, params, map[string]InputFile{fieldname: FilePath(filename)})
+}
 
-	f, err := os.Open(filename)
-	if err != nil {
-		return APIResponse{}, err
-	}
+// UploadFiles makes a request to the API with several files, such as a video
+// and its thumbnail. It is UploadFilesProgress without progress reporting.
+func (bot *BotAPI) UploadFiles(endpoint32 string, params map[string]string, files map[string]InputFile) (APIResponse, error) {
+	return bot.UploadFilesProgress(endpoint32, params, files, nil)
+}
 
-	fw, err := w.CreateFormFile(fieldname, filename)
-	if err != nil {
-		return APIResponse{}, err
-	}
+// UploadFilesProgress makes a request to the API with several files, such as
+// a video and its thumbnail. files maps each parameter name to its file, all
+// of which must need uploading.
+//
+// The files are streamed to Telegram rather than read into memory. Requests
+// which fail before they have been sent in full are retried up to
+// UploadRetries times, so long as every file can be read again; if one can't,
+// ErrNotRewindable is returned. Once a request has been sent Telegram may
+// have acted on it, so it isn't retried, which would send the message twice.
+//
+// progress, if not nil, is called as the files are sent, starting over from
+// zero if the request is retried.
+//
+// Requires the parameters to hold the files not be in the params.
+func (bot *BotAPI) UploadFilesProgress(endpoint32 string, params map[string]string, files map[string]InputFile, progress ProgressFunc) (APIResponse, error) {
+	offsets := make(map[string]int64)
+	rewindable := true
+	var total int64
+	for fieldname, file := range files {
+		offset, err := file.offset()
+		if err != nil {
+			return APIResponse{}, err
+		}
 
-	if _, err = io.Copy(fw, f); err != nil {
-		return APIResponse{}, err
+		offsets[fieldname] = offset
+		rewindable = rewindable && file.rewindable()
+
+		if size := file.size(offset); size < 0 || total < 0 {
+			total = -1
+		} else {
+			total += size
+		}
 	}
 
-	for key, val := range params {
-		if fw, err = w.CreateFormField(key); err != nil {
-			return APIResponse{}, err
+	client := &http.Client{}
+
+	var res *http.Response
+	for attempt := 0; ; attempt++ {
+		retry := attempt > 0
+		var sent int64
+		open := func(fieldname string, file InputFile) (io.ReadCloser, error) {
+			var f io.ReadCloser
+			var err error
+			if !retry {
+				f, err = file.open()
+			} else {
+				f, err = file.reopen(offsets[fieldname])
+			}
+
+			if err != nil || progress == nil {
+				return f, err
+			}
+
+			return progressReader{
+				ReadCloser: f,
+				report: func(n int) {
+					sent += int64(n)
+					progress(sent, total)
+				},
+			}, nil
+		}
+		if progress != nil {
+			progress(0, total)
 		}
 
-		if _, err = fw.Write([]byte(val)); err != nil {
-			return APIResponse{}, err
+		// The files are written into the request body as it is sent, and
+		// done is closed once nothing is reading from them any more.
+		body, w := io.Pipe()
+		mw := multipart.NewWriter(w)
+		done := make(chan struct{})
+
+		go func() {
+			w.CloseWithError(writeMultipart(mw, params, files, open))
+			close(done)
+		}()
+
+		// wrote is set once the whole request has been sent.
+		var wrote int32
+		trace := &httptrace.ClientTrace{
+			WroteRequest: func(info httptrace.WroteRequestInfo) {
+				if info.Err == nil {
+					atomic.StoreInt32(&wrote, 1)
+				}
+			},
 		}
-	}
 
-	w.Close()
+		req, err := http.NewRequest("POST", "https://api.telegram.org/bot"+bot.Token+"/"+endpoint32, body)
+		if err == nil {
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+			req.Header.Set("Content-Type", mw.FormDataContentType())
 
-	req, err := http.NewRequest("POST", "https://api.telegram.org/bot"+bot.Token+"/"+endpoint32, &b)
-	if err != nil {
-		return APIResponse{}, err
-	}
+			res, err = client.Do(req)
+			if err == nil {
+				break
+			}
+		}
 
-	req.Header.Set("Content-Type", w.FormDataContentType())
+		body.Close()
+		<-done
 
-	client := &http.Client{}
-	res, err := client.Do(req)
-	if err != nil {
-		return APIResponse{}, err
+		if attempt >= bot.UploadRetries || atomic.LoadInt32(&wrote) != 0 {
+			return APIResponse{}, err
+		}
+		if !rewindable {
+			return APIResponse{}, fmt.Errorf("%w: %v", ErrNotRewindable, err)
+		}
+
+		if bot.Debug {
+			log.Printf("%s upload failed, retrying: %v\n", endpoint32, err)
+		}
 	}
+	defer res.Body.Close()
 
 	bytes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
@@ -217,75 +528,97 @@ func (bot *BotAPI) UploadFile(endpoint32 string, params map[string]string, field
 	var apiResp APIResponse
 	json.Unmarshal(bytes, &apiResp)
 
+	if !apiResp.Ok {
+		if apiResp.Description == "" {
+			// Such as a gateway error, which isn't from the API itself.
+			apiResp.ErrorCode = int32(res.StatusCode)
+			apiResp.Description = res.Status
+		}
+
+		return APIResponse{}, apiResp.newError()
+	}
+
 	return apiResp, nil
 }
 
-// GetMe fetches the currently authenticated bot.
-//
-// There are no parameters for this method.
-func (bot *BotAPI) GetMe() (User, error) {
-	resp, err := bot.MakeRequest("getMe", nil)
-	if err != nil {
-		return User{}, err
+// writeMultipart writes params and then files to w, opening each file with
+// open, and closes w once done.
+func writeMultipart(w *multipart.Writer, params map[string]string, files map[string]InputFile, open func(string, InputFile) (io.ReadCloser, error)) error {
+	for key, val := range params {
+		if err := w.WriteField(key, val); err != nil {
+			return err
+		}
 	}
 
-	var user User
-	json.Unmarshal(resp.Result, &user)
+	for fieldname, file := range files {
+		f, err := open(fieldname, file)
+		if err != nil {
+			return err
+		}
 
-	if bot.Debug {
-		log.Printf("getMe: %+v\n", user)
+		fw, err := w.CreateFormFile(fieldname, file.Name())
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		_, err = io.Copy(fw, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
 	}
 
-	return user, nil
+	return w.Close()
 }
 
-// SendMessage sends a Message to a chat.
-//
-// Requires ChatID and Text.
-// DisableWebPagePreview, ReplyToMessageID, and ReplyMarkup are optional.
-func (bot *BotAPI) SendMessage(config MessageConfig) (Message, error) {
-	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-	v.Add("text", config.Text)
-	v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
-	if config.ReplyToMessageID != 0 {
-		v.Add("reply_to_message_id", strconv.Itoa(int(config.ReplyToMessageID)))
+// Values adds the options which are set to a request's parameters.
+func (opts SendOptions) Values(v url.Values) error {
+	if opts.MessageThreadID != 0 {
+		v.Add("message_thread_id", strconv.Itoa(int(opts.MessageThreadID)))
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
+	if opts.ReplyParameters != nil {
+		data, err := json.Marshal(opts.ReplyParameters)
 		if err != nil {
-			return Message{}, err
+			return err
 		}
 
-		v.Add("reply_markup", string(data))
+		v.Add("reply_parameters", string(data))
+	} else if opts.ReplyToMessageID != 0 {
+		v.Add("reply_to_message_id", strconv.Itoa(int(opts.ReplyToMessageID)))
 	}
-
-	resp, err := bot.MakeRequest("SendMessage", v)
-	if err != nil {
-		return Message{}, err
+	if opts.AllowSendingWithoutReply {
+		v.Add("allow_sending_without_reply", "true")
 	}
+	if opts.DisableNotification {
+		v.Add("disable_notification", "true")
+	}
+	if opts.ProtectContent {
+		v.Add("protect_content", "true")
+	}
+	if opts.LinkPreviewOptions != nil {
+		data, err := json.Marshal(opts.LinkPreviewOptions)
+		if err != nil {
+			return err
+		}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+		v.Add("link_preview_options", string(data))
+	}
+	if opts.ReplyMarkup != nil {
+		data, err := json.Marshal(opts.ReplyMarkup)
+		if err != nil {
+			return err
+		}
 
-	if bot.Debug {
-		log.Printf("SendMessage req : %+v\n", v)
-		log.Printf("SendMessage resp: %+v\n", message)
+		v.Add("reply_markup", string(data))
 	}
 
-	return message, nil
+	return nil
 }
 
-// ForwardMessage forwards a message from one chat to another.
-//
-// Requires ChatID (destionation), FromChatID (source), and MessageID.
-func (bot *BotAPI) ForwardMessage(config ForwardConfig) (Message, error) {
-	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-	v.Add("from_chat_id", strconv.Itoa(int(config.FromChatID)))
-	v.Add("message_id", strconv.Itoa(int(config.MessageID)))
-
-	resp, err := bot.MakeRequest("forwardMessage", v)
+// send makes a request to an endpoint which returns the Message it sent.
+func (bot *BotAPI) send(endpoint string, v url.Values) (Message, error) {
+	resp, err := bot.MakeRequest(endpoint, v)
 	if err != nil {
 		return Message{}, err
 	}
@@ -294,71 +627,45 @@ func (bot *BotAPI) ForwardMessage(config ForwardConfig) (Message, error) {
 	json.Unmarshal(resp.Result, &message)
 
 	if bot.Debug {
-		log.Printf("forwardMessage req : %+v\n", v)
-		log.Printf("forwardMessage resp: %+v\n", message)
+		log.Printf("%s req : %+v\n", endpoint, v)
+		log.Printf("%s resp: %+v\n", endpoint, message)
 	}
 
 	return message, nil
 }
 
-// SendPhoto sends or uploads a photo to a chat.
+// upload is like send, but also sends files, which maps each parameter name
+// to its file. Files which need uploading are sent in a multipart request,
+// reporting to progress, and the rest are added to the request by reference.
 //
-// Requires ChatID and FileID OR FilePath.
-// Caption, ReplyToMessageID, and ReplyMarkup are optional.
-func (bot *BotAPI) SendPhoto(config PhotoConfig) (Message, error) {
-	if config.UseExistingPhoto {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-		v.Add("photo", config.FileID)
-		if config.Caption != "" {
-			v.Add("caption", config.Caption)
-		}
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(int(config.ChatID)))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
-
-			v.Add("reply_markup", string(data))
-		}
-
-		resp, err := bot.MakeRequest("SendPhoto", v)
-		if err != nil {
-			return Message{}, err
-		}
+// If the bot has an UploadCache, files already uploaded are sent by their
+// FileID instead. If Telegram refuses one of those FileIDs, the files are
+// uploaded after all; other errors are returned as they are.
+func (bot *BotAPI) upload(endpoint string, v url.Values, files map[string]InputFile, progress ProgressFunc) (Message, error) {
+	if bot.UploadCache == nil {
+		return bot.uploadFiles(endpoint, v, files, progress)
+	}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+	lookup := bot.UploadCache.lookup(bot, files)
 
+	message, err := bot.uploadFiles(endpoint, v, lookup.files, progress)
+	if isFileIDRejection(err) && lookup.forget(files) {
 		if bot.Debug {
-			log.Printf("SendPhoto req : %+v\n", v)
-			log.Printf("SendPhoto resp: %+v\n", message)
+			log.Printf("%s with cached files failed, uploading them: %v\n", endpoint, err)
 		}
 
-		return message, nil
+		return bot.uploadFiles(endpoint, v, files, progress)
+	} else if err != nil {
+		return Message{}, err
 	}
 
-	params := make(map[string]string)
-	params["chat_id"] = strconv.Itoa(int(config.ChatID))
-	if config.Caption != "" {
-		params["caption"] = config.Caption
-	}
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(int(config.ReplyToMessageID))
-	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
+	lookup.remember(message)
 
-		params["reply_markup"] = string(data)
-	}
+	return message, nil
+}
 
-	resp, err := bot.UploadFile("SendPhoto", params, "photo", config.FilePath)
+func (bot *BotAPI) uploadFiles(endpoint string, v url.Values, files map[string]InputFile, progress ProgressFunc) (Message, error) {
+	resp, err := bot.request(endpoint, v, files, progress)
 	if err != nil {
 		return Message{}, err
 	}
@@ -367,268 +674,554 @@ func (bot *BotAPI) SendPhoto(config PhotoConfig) (Message, error) {
 	json.Unmarshal(resp.Result, &message)
 
 	if bot.Debug {
-		log.Printf("SendPhoto resp: %+v\n", message)
+		log.Printf("%s resp: %+v\n", endpoint, message)
 	}
 
 	return message, nil
 }
 
-// SendAudio sends or uploads an audio clip to a chat.
-// If using a file, the file must be encoded as an .ogg with OPUS.
-//
-// Requires ChatID and FileID OR FilePath.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *BotAPI) SendAudio(config AudioConfig) (Message, error) {
-	if config.UseExistingAudio {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-		v.Add("audio", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(int(config.ReplyToMessageID)))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
+// request makes a request with files, in a multipart request if any of them
+// need uploading.
+func (bot *BotAPI) request(endpoint string, v url.Values, files map[string]InputFile, progress ProgressFunc) (APIResponse, error) {
+	params := make(map[string]string)
+	for key := range v {
+		params[key] = v.Get(key)
+	}
 
-			v.Add("reply_markup", string(data))
+	uploads := make(map[string]InputFile)
+	for fieldname, file := range files {
+		if file.NeedsUpload() {
+			uploads[fieldname] = file
+		} else {
+			params[fieldname] = file.value()
 		}
+	}
 
-		resp, err := bot.MakeRequest("sendAudio", v)
-		if err != nil {
-			return Message{}, err
+	if len(uploads) == 0 {
+		v := url.Values{}
+		for key, val := range params {
+			v.Set(key, val)
 		}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+		return bot.MakeRequest(endpoint, v)
+	}
 
-		if bot.Debug {
-			log.Printf("sendAudio req : %+v\n", v)
-			log.Printf("sendAudio resp: %+v\n", message)
-		}
+	return bot.UploadFilesProgress(endpoint, params, uploads, progress)
+}
 
-		return message, nil
+// GetMe fetches the currently authenticated bot.
+//
+// There are no parameters for this method.
+func (bot *BotAPI) GetMe() (User, error) {
+	resp, err := bot.MakeRequest("getMe", nil)
+	if err != nil {
+		return User{}, err
 	}
 
-	params := make(map[string]string)
+	var user User
+	json.Unmarshal(resp.Result, &user)
 
-	params["chat_id"] = strconv.Itoa(int(config.ChatID))
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(int(config.ReplyToMessageID))
+	if bot.Debug {
+		log.Printf("getMe: %+v\n", user)
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
 
-		params["reply_markup"] = string(data)
-	}
+	return user, nil
+}
 
-	resp, err := bot.UploadFile("sendAudio", params, "audio", config.FilePath)
-	if err != nil {
+// SendMessage sends a Message to a chat.
+//
+// Requires ChatID and Text.
+// DisableWebPagePreview and the SendOptions are optional.
+func (bot *BotAPI) SendMessage(config MessageConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("text", config.Text)
+	if config.DisableWebPagePreview {
+		v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
+	}
+	if err := config.SendOptions.Values(v); err != nil {
 		return Message{}, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+	return bot.send("SendMessage", v)
+}
 
-	if bot.Debug {
-		log.Printf("sendAudio resp: %+v\n", message)
+// ForwardMessage forwards a message from one chat to another.
+//
+// Requires ChatID (destionation), FromChatID (source), and MessageID.
+// MessageThreadID, DisableNotification and ProtectContent are optional.
+func (bot *BotAPI) ForwardMessage(config ForwardConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("from_chat_id", config.FromChatID.String())
+	v.Add("message_id", strconv.Itoa(int(config.MessageID)))
+	if config.MessageThreadID != 0 {
+		v.Add("message_thread_id", strconv.Itoa(int(config.MessageThreadID)))
+	}
+	if config.DisableNotification {
+		v.Add("disable_notification", "true")
+	}
+	if config.ProtectContent {
+		v.Add("protect_content", "true")
 	}
 
-	return message, nil
+	return bot.send("forwardMessage", v)
 }
 
-// SendDocument sends or uploads a document to a chat.
+// SendPhoto sends or uploads a photo to a chat.
 //
-// Requires ChatID and FileID OR FilePath.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *BotAPI) SendDocument(config DocumentConfig) (Message, error) {
-	if config.UseExistingDocument {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-		v.Add("document", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(int(config.ReplyToMessageID)))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
-
-			v.Add("reply_markup", string(data))
-		}
-
-		resp, err := bot.MakeRequest("sendDocument", v)
-		if err != nil {
-			return Message{}, err
-		}
+// Requires ChatID and File.
+// Caption, ParseMode, HasSpoiler, Progress and the SendOptions are optional.
+func (bot *BotAPI) SendPhoto(config PhotoConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if config.HasSpoiler {
+		v.Add("has_spoiler", "true")
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+	return bot.upload("SendPhoto", v, map[string]InputFile{"photo": config.File}, config.Progress)
+}
 
-		if bot.Debug {
-			log.Printf("sendDocument req : %+v\n", v)
-			log.Printf("sendDocument resp: %+v\n", message)
-		}
+// SendAudio sends or uploads an audio clip to a chat.
+// If using a file, the file must be encoded as an .ogg with OPUS.
+//
+// Requires ChatID and File.
+// Caption, ParseMode, Duration, Performer, Title, Thumbnail (a JPEG to
+// upload), Progress and the SendOptions are optional.
+func (bot *BotAPI) SendAudio(config AudioConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if config.Duration != 0 {
+		v.Add("duration", strconv.Itoa(int(config.Duration)))
+	}
+	if config.Performer != "" {
+		v.Add("performer", config.Performer)
+	}
+	if config.Title != "" {
+		v.Add("title", config.Title)
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-		return message, nil
+	files := map[string]InputFile{"audio": config.File}
+	if !config.Thumbnail.IsZero() {
+		files["thumbnail"] = config.Thumbnail
 	}
 
-	params := make(map[string]string)
+	return bot.upload("sendAudio", v, files, config.Progress)
+}
 
-	params["chat_id"] = strconv.Itoa(int(config.ChatID))
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(int(config.ReplyToMessageID))
+// SendDocument sends or uploads a document to a chat.
+//
+// Requires ChatID and File.
+// Caption, ParseMode, Thumbnail (a JPEG to upload),
+// DisableContentTypeDetection, Progress and the SendOptions are optional.
+func (bot *BotAPI) SendDocument(config DocumentConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if config.DisableContentTypeDetection {
+		v.Add("disable_content_type_detection", "true")
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
 
-		params["reply_markup"] = string(data)
+	files := map[string]InputFile{"document": config.File}
+	if !config.Thumbnail.IsZero() {
+		files["thumbnail"] = config.Thumbnail
 	}
 
-	resp, err := bot.UploadFile("sendDocument", params, "document", config.FilePath)
-	if err != nil {
+	return bot.upload("sendDocument", v, files, config.Progress)
+}
+
+// SendSticker sends or uploads a sticker to a chat.
+//
+// Requires ChatID and File.
+// Progress and the SendOptions are optional.
+func (bot *BotAPI) SendSticker(config StickerConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if err := config.SendOptions.Values(v); err != nil {
 		return Message{}, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+	return bot.upload("sendSticker", v, map[string]InputFile{"sticker": config.File}, config.Progress)
+}
 
-	if bot.Debug {
-		log.Printf("sendDocument resp: %+v\n", message)
+// SendVideo sends or uploads a video to a chat.
+//
+// Requires ChatID and File.
+// Caption, ParseMode, Duration, Width, Height, Thumbnail (a JPEG to
+// upload), SupportsStreaming, HasSpoiler, Progress and the SendOptions are
+// optional.
+func (bot *BotAPI) SendVideo(config VideoConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if config.Duration != 0 {
+		v.Add("duration", strconv.Itoa(int(config.Duration)))
+	}
+	if config.Width != 0 {
+		v.Add("width", strconv.Itoa(int(config.Width)))
+	}
+	if config.Height != 0 {
+		v.Add("height", strconv.Itoa(int(config.Height)))
+	}
+	if config.SupportsStreaming {
+		v.Add("supports_streaming", "true")
+	}
+	if config.HasSpoiler {
+		v.Add("has_spoiler", "true")
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
 	}
 
-	return message, nil
+	files := map[string]InputFile{"video": config.File}
+	if !config.Thumbnail.IsZero() {
+		files["thumbnail"] = config.Thumbnail
+	}
+
+	return bot.upload("sendVideo", v, files, config.Progress)
 }
 
-// SendSticker sends or uploads a sticker to a chat.
+// SendVoice sends or uploads a voice message to a chat.
+// If using a file, the file must be encoded as an .ogg with OPUS.
 //
-// Requires ChatID and FileID OR FilePath.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *BotAPI) SendSticker(config StickerConfig) (Message, error) {
-	if config.UseExistingSticker {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-		v.Add("sticker", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(int(config.ReplyToMessageID)))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
+// Requires ChatID and File.
+// Caption, ParseMode, Duration, Progress and the SendOptions are optional.
+func (bot *BotAPI) SendVoice(config VoiceConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if config.Duration != 0 {
+		v.Add("duration", strconv.Itoa(int(config.Duration)))
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-			v.Add("reply_markup", string(data))
-		}
+	return bot.upload("sendVoice", v, map[string]InputFile{"voice": config.File}, config.Progress)
+}
 
-		resp, err := bot.MakeRequest("sendSticker", v)
-		if err != nil {
-			return Message{}, err
-		}
+// SendAnimation sends or uploads an animation, a GIF or silent MPEG-4 video,
+// to a chat.
+//
+// Requires ChatID and File.
+// Caption, ParseMode, Duration, Width, Height, Thumbnail (a JPEG to
+// upload), HasSpoiler, Progress and the SendOptions are optional.
+func (bot *BotAPI) SendAnimation(config AnimationConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if config.Duration != 0 {
+		v.Add("duration", strconv.Itoa(int(config.Duration)))
+	}
+	if config.Width != 0 {
+		v.Add("width", strconv.Itoa(int(config.Width)))
+	}
+	if config.Height != 0 {
+		v.Add("height", strconv.Itoa(int(config.Height)))
+	}
+	if config.HasSpoiler {
+		v.Add("has_spoiler", "true")
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+	files := map[string]InputFile{"animation": config.File}
+	if !config.Thumbnail.IsZero() {
+		files["thumbnail"] = config.Thumbnail
+	}
 
-		if bot.Debug {
-			log.Printf("sendSticker req : %+v\n", v)
-			log.Printf("sendSticker resp: %+v\n", message)
+	return bot.upload("sendAnimation", v, files, config.Progress)
+}
+
+// SendVideoNote sends or uploads a video note, a short round video, to a
+// chat. If using a file, it must be a square MPEG-4 video of up to a minute.
+//
+// Requires ChatID and File.
+// Duration, Length (the diameter of the video), Thumbnail (a JPEG to
+// upload), Progress and the SendOptions are optional.
+func (bot *BotAPI) SendVideoNote(config VideoNoteConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	if config.Duration != 0 {
+		v.Add("duration", strconv.Itoa(int(config.Duration)))
+	}
+	if config.Length != 0 {
+		v.Add("length", strconv.Itoa(int(config.Length)))
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
+
+	files := map[string]InputFile{"video_note": config.File}
+	if !config.Thumbnail.IsZero() {
+		files["thumbnail"] = config.Thumbnail
+	}
+
+	return bot.upload("sendVideoNote", v, files, config.Progress)
+}
+
+// inputMedia is the JSON form of an InputMedia.
+type inputMedia struct {
+	Type              string          `json:"type"`
+	Media             string          `json:"media"`
+	Thumbnail         string          `json:"thumbnail,omitempty"`
+	Caption           string          `json:"caption,omitempty"`
+	ParseMode         string          `json:"parse_mode,omitempty"`
+	CaptionEntities   []MessageEntity `json:"caption_entities,omitempty"`
+	HasSpoiler        bool            `json:"has_spoiler,omitempty"`
+	Width             int32           `json:"width,omitempty"`
+	Height            int32           `json:"height,omitempty"`
+	Duration          int32           `json:"duration,omitempty"`
+	SupportsStreaming bool            `json:"supports_streaming,omitempty"`
+}
+
+// encode returns the JSON form of media, adding the files which need
+// uploading to files, named with suffix.
+func (media InputMedia) encode(suffix string, files map[string]InputFile) inputMedia {
+	// attach refers to a file uploaded in the same request, or returns the
+	// reference to a file that needs no upload.
+	attach := func(fieldname string, file InputFile) string {
+		if !file.NeedsUpload() {
+			return file.value()
 		}
 
-		return message, nil
+		files[fieldname] = file
+		return "attach://" + fieldname
 	}
 
-	params := make(map[string]string)
+	encoded := inputMedia{
+		Type:              media.Type,
+		Media:             attach("file"+suffix, media.Media),
+		Caption:           media.Caption,
+		ParseMode:         media.ParseMode,
+		CaptionEntities:   media.CaptionEntities,
+		HasSpoiler:        media.HasSpoiler,
+		Width:             media.Width,
+		Height:            media.Height,
+		Duration:          media.Duration,
+		SupportsStreaming: media.SupportsStreaming,
+	}
+
+	if !media.Thumbnail.IsZero() {
+		encoded.Thumbnail = attach("thumbnail"+suffix, media.Thumbnail)
+	}
+
+	return encoded
+}
 
-	params["chat_id"] = strconv.Itoa(int(config.ChatID))
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(int(config.ReplyToMessageID))
+// SendMediaGroup sends between 2 and 10 photos and videos to a chat as an
+// album, returning a Message for each of them. All of the files which need
+// uploading are sent in a single request; the UploadCache isn't used.
+//
+// Requires ChatID and Media.
+// Progress and the SendOptions are optional, other than ReplyMarkup, which
+// Telegram doesn't allow on albums and is an error to set.
+func (bot *BotAPI) SendMediaGroup(config MediaGroupConfig) ([]Message, error) {
+	if len(config.Media) < 2 || len(config.Media) > 10 {
+		return nil, errors.New("tgbotapi: a media group must have between 2 and 10 items")
 	}
+
 	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
+		return nil, errors.New("tgbotapi: a media group can't have a reply markup")
+	}
+
+	files := make(map[string]InputFile)
 
-		params["reply_markup"] = string(data)
+	media := make([]inputMedia, len(config.Media))
+	for i, item := range config.Media {
+		media[i] = item.encode(strconv.Itoa(i), files)
 	}
 
-	resp, err := bot.UploadFile("sendSticker", params, "sticker", config.FilePath)
+	data, err := json.Marshal(media)
 	if err != nil {
-		return Message{}, err
+		return nil, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("media", string(data))
+	if err := config.SendOptions.Values(v); err != nil {
+		return nil, err
+	}
+
+	resp, err := bot.request("sendMediaGroup", v, files, config.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []Message
+	json.Unmarshal(resp.Result, &messages)
 
 	if bot.Debug {
-		log.Printf("sendSticker resp: %+v\n", message)
+		log.Printf("sendMediaGroup req : %+v\n", v)
+		log.Printf("sendMediaGroup resp: %+v\n", messages)
 	}
 
-	return message, nil
+	return messages, nil
 }
 
-// SendVideo sends or uploads a video to a chat.
+// SendLocation sends a location to a chat.
 //
-// Requires ChatID and FileID OR FilePath.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *BotAPI) SendVideo(config VideoConfig) (Message, error) {
-	if config.UseExistingVideo {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-		v.Add("video", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(int(config.ReplyToMessageID)))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
+// Requires ChatID, Latitude, and Longitude.
+// The SendOptions are optional.
+func (bot *BotAPI) SendLocation(config LocationConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("latitude", strconv.FormatFloat(config.Latitude, 'f', 6, 64))
+	v.Add("longitude", strconv.FormatFloat(config.Longitude, 'f', 6, 64))
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-			v.Add("reply_markup", string(data))
-		}
+	return bot.send("sendLocation", v)
+}
 
-		resp, err := bot.MakeRequest("sendVideo", v)
-		if err != nil {
-			return Message{}, err
-		}
+// SendContact sends a phone contact to a chat.
+//
+// Requires ChatID, PhoneNumber and FirstName.
+// LastName and the SendOptions are optional.
+func (bot *BotAPI) SendContact(config ContactConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("phone_number", config.PhoneNumber)
+	v.Add("first_name", config.FirstName)
+	if config.LastName != "" {
+		v.Add("last_name", config.LastName)
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+	return bot.send("sendContact", v)
+}
 
-		if bot.Debug {
-			log.Printf("sendVideo req : %+v\n", v)
-			log.Printf("sendVideo resp: %+v\n", message)
-		}
+// SendVenue sends a venue, a named and addressed location, to a chat.
+//
+// Requires ChatID, Latitude, Longitude, Title and Address.
+// FoursquareID and the SendOptions are optional.
+func (bot *BotAPI) SendVenue(config VenueConfig) (Message, error) {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("latitude", strconv.FormatFloat(config.Latitude, 'f', 6, 64))
+	v.Add("longitude", strconv.FormatFloat(config.Longitude, 'f', 6, 64))
+	v.Add("title", config.Title)
+	v.Add("address", config.Address)
+	if config.FoursquareID != "" {
+		v.Add("foursquare_id", config.FoursquareID)
+	}
+	if err := config.SendOptions.Values(v); err != nil {
+		return Message{}, err
+	}
 
-		return message, nil
+	return bot.send("sendVenue", v)
+}
+
+// SendChatAction sets a current action in a chat.
+//
+// Requires ChatID and a valid Action (see Chat constants).
+func (bot *BotAPI) SendChatAction(config ChatActionConfig) error {
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("action", string(config.Action))
+
+	_, err := bot.MakeRequest("sendChatAction", v)
+	if err != nil {
+		return err
 	}
 
-	params := make(map[string]string)
+	return nil
+}
+
+// ChatActionProgress returns a ProgressFunc which shows action in chat while
+// an upload is in progress, such as ChatUploadDocument while sending a
+// document. next, if not nil, is passed the progress as well.
+func (bot *BotAPI) ChatActionProgress(chat ChatRef, action ChatAction, next ProgressFunc) ProgressFunc {
+	var last time.Time
+
+	return func(sent, total int64) {
+		// Actions last for 5 seconds, so refresh it a little before then.
+		if sent != total && time.Since(last) >= 4*time.Second {
+			last = time.Now()
 
-	params["chat_id"] = strconv.Itoa(int(config.ChatID))
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(int(config.ReplyToMessageID))
+			go bot.SendChatAction(NewChatAction(chat, action))
+		}
+
+		if next != nil {
+			next(sent, total)
+		}
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
+}
+
+// values adds the target to a request's parameters.
+func (target EditTarget) values(v url.Values) {
+	if target.InlineMessageID != "" {
+		v.Add("inline_message_id", target.InlineMessageID)
+		return
+	}
+
+	v.Add("chat_id", target.ChatID.String())
+	v.Add("message_id", strconv.Itoa(int(target.MessageID)))
+}
+
+// edit makes a request to an endpoint which edits a message, returning the
+// edited message. Edits which leave the message unchanged succeed, returning
+// the zero Message, as do edits of inline messages.
+func (bot *BotAPI) edit(endpoint string, v url.Values, markup *InlineKeyboardMarkup, files map[string]InputFile, progress ProgressFunc) (Message, error) {
+	if markup != nil {
+		data, err := json.Marshal(markup)
 		if err != nil {
 			return Message{}, err
 		}
 
-		params["reply_markup"] = string(data)
+		v.Add("reply_markup", string(data))
 	}
 
-	resp, err := bot.UploadFile("sendVideo", params, "video", config.FilePath)
-	if err != nil {
+	resp, err := bot.request(endpoint, v, files, progress)
+	if IsNotModified(err) {
+		return Message{}, nil
+	} else if err != nil {
 		return Message{}, err
 	}
 
@@ -636,63 +1229,157 @@ func (bot *BotAPI) SendVideo(config VideoConfig) (Message, error) {
 	json.Unmarshal(resp.Result, &message)
 
 	if bot.Debug {
-		log.Printf("sendVideo resp: %+v\n", message)
+		log.Printf("%s req : %+v\n", endpoint, v)
+		log.Printf("%s resp: %+v\n", endpoint, message)
 	}
 
 	return message, nil
 }
 
-// SendLocation sends a location to a chat.
+// EditMessageText changes the text of a message.
 //
-// Requires ChatID, Latitude, and Longitude.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *BotAPI) SendLocation(config LocationConfig) (Message, error) {
+// Requires the EditTarget and Text.
+// ParseMode, Entities, LinkPreviewOptions and ReplyMarkup are optional.
+func (bot *BotAPI) EditMessageText(config EditMessageTextConfig) (Message, error) {
 	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-	v.Add("latitude", strconv.FormatFloat(config.Latitude, 'f', 6, 64))
-	v.Add("longitude", strconv.FormatFloat(config.Longitude, 'f', 6, 64))
-	if config.ReplyToMessageID != 0 {
-		v.Add("reply_to_message_id", strconv.Itoa(int(config.ReplyToMessageID)))
+	config.EditTarget.values(v)
+	v.Add("text", config.Text)
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
+	if len(config.Entities) > 0 {
+		data, err := json.Marshal(config.Entities)
 		if err != nil {
 			return Message{}, err
 		}
 
-		v.Add("reply_markup", string(data))
+		v.Add("entities", string(data))
+	}
+	if config.LinkPreviewOptions != nil {
+		data, err := json.Marshal(config.LinkPreviewOptions)
+		if err != nil {
+			return Message{}, err
+		}
+
+		v.Add("link_preview_options", string(data))
+	}
+
+	return bot.edit("editMessageText", v, config.ReplyMarkup, nil, nil)
+}
+
+// EditMessageCaption changes the caption of a message with media. An empty
+// Caption removes it.
+//
+// Requires the EditTarget.
+// Caption, ParseMode, CaptionEntities and ReplyMarkup are optional.
+func (bot *BotAPI) EditMessageCaption(config EditMessageCaptionConfig) (Message, error) {
+	v := url.Values{}
+	config.EditTarget.values(v)
+	v.Add("caption", config.Caption)
+	if config.ParseMode != "" {
+		v.Add("parse_mode", config.ParseMode)
+	}
+	if len(config.CaptionEntities) > 0 {
+		data, err := json.Marshal(config.CaptionEntities)
+		if err != nil {
+			return Message{}, err
+		}
+
+		v.Add("caption_entities", string(data))
 	}
 
-	resp, err := bot.MakeRequest("sendLocation", v)
+	return bot.edit("editMessageCaption", v, config.ReplyMarkup, nil, nil)
+}
+
+// EditMessageMedia replaces the photo, video, animation, audio or document
+// of a message, uploading it if needed.
+//
+// Requires the EditTarget and Media.
+// ReplyMarkup and Progress are optional.
+func (bot *BotAPI) EditMessageMedia(config EditMessageMediaConfig) (Message, error) {
+	files := make(map[string]InputFile)
+
+	data, err := json.Marshal(config.Media.encode("", files))
 	if err != nil {
 		return Message{}, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+	v := url.Values{}
+	config.EditTarget.values(v)
+	v.Add("media", string(data))
 
-	if bot.Debug {
-		log.Printf("sendLocation req : %+v\n", v)
-		log.Printf("sendLocation resp: %+v\n", message)
-	}
+	return bot.edit("editMessageMedia", v, config.ReplyMarkup, files, config.Progress)
+}
 
-	return message, nil
+// EditMessageReplyMarkup changes the inline keyboard of a message.
+//
+// Requires the EditTarget.
+// ReplyMarkup is optional; leaving it nil removes the keyboard.
+func (bot *BotAPI) EditMessageReplyMarkup(config EditMessageReplyMarkupConfig) (Message, error) {
+	v := url.Values{}
+	config.EditTarget.values(v)
+
+	return bot.edit("editMessageReplyMarkup", v, config.ReplyMarkup, nil, nil)
 }
 
-// SendChatAction sets a current action in a chat.
+// DeleteMessage deletes a message. Bots can delete their own messages, and
+// in groups where they are an administrator with the right to, others'.
 //
-// Requires ChatID and a valid Action (see Chat constants).
-func (bot *BotAPI) SendChatAction(config ChatActionConfig) error {
+// Requires ChatID and MessageID.
+func (bot *BotAPI) DeleteMessage(config DeleteMessageConfig) error {
 	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(int(config.ChatID)))
-	v.Add("action", string(config.Action))
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("message_id", strconv.Itoa(int(config.MessageID)))
 
-	_, err := bot.MakeRequest("sendChatAction", v)
+	_, err := bot.MakeRequest("deleteMessage", v)
+
+	return err
+}
+
+// DeleteMessages deletes up to 100 messages from a chat at once. Messages
+// which can't be deleted are skipped.
+//
+// Requires ChatID and MessageIDs.
+func (bot *BotAPI) DeleteMessages(config DeleteMessagesConfig) error {
+	data, err := json.Marshal(config.MessageIDs)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	v := url.Values{}
+	v.Add("chat_id", config.ChatID.String())
+	v.Add("message_ids", string(data))
+
+	_, err = bot.MakeRequest("deleteMessages", v)
+
+	return err
+}
+
+// AnswerCallbackQuery answers a press of an inline keyboard button, which
+// must be done for the user's client to stop showing it as in progress.
+//
+// Requires CallbackQueryID.
+// Text (shown as a notification, or an alert if ShowAlert is set), URL and
+// CacheTime are optional.
+func (bot *BotAPI) AnswerCallbackQuery(config CallbackConfig) error {
+	v := url.Values{}
+	v.Add("callback_query_id", config.CallbackQueryID)
+	if config.Text != "" {
+		v.Add("text", config.Text)
+	}
+	if config.ShowAlert {
+		v.Add("show_alert", "true")
+	}
+	if config.URL != "" {
+		v.Add("url", config.URL)
+	}
+	if config.CacheTime != 0 {
+		v.Add("cache_time", strconv.Itoa(int(config.CacheTime)))
+	}
+
+	_, err := bot.MakeRequest("answerCallbackQuery", v)
+
+	return err
 }
 
 // GetUserProfilePhotos gets a user's profile photos.
@@ -701,7 +1388,7 @@ func (bot *BotAPI) SendChatAction(config ChatActionConfig) error {
 // Offset and Limit are optional.
 func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserProfilePhotos, error) {
 	v := url.Values{}
-	v.Add("user_id", strconv.Itoa(int(config.UserID)))
+	v.Add("user_id", strconv.FormatInt(config.UserID, 10))
 	if config.Offset != 0 {
 		v.Add("offset", strconv.Itoa(int(config.Offset)))
 	}
@@ -725,6 +1412,67 @@ func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserPro
 	return profilePhotos, nil
 }
 
+// GetFile gets the information needed to download a file.
+// Bots can download files of up to 20MB.
+//
+// Requires FileID.
+func (bot *BotAPI) GetFile(config FileConfig) (File, error) {
+	return bot.GetFileContext(context.Background(), config)
+}
+
+// GetFileContext is GetFile, which gives up when ctx is done.
+func (bot *BotAPI) GetFileContext(ctx context.Context, config FileConfig) (File, error) {
+	v := url.Values{}
+	v.Add("file_id", config.FileID)
+
+	resp, err := bot.MakeRequestContext(ctx, "getFile", v)
+	if err != nil {
+		return File{}, err
+	}
+
+	var file File
+	json.Unmarshal(resp.Result, &file)
+
+	if bot.Debug {
+		log.Printf("getFile req : %+v\n", v)
+		log.Printf("getFile resp: %+v\n", file)
+	}
+
+	return file, nil
+}
+
+// Download streams the contents of the file with the given fileID into w.
+func (bot *BotAPI) Download(ctx context.Context, fileID string, w io.Writer) error {
+	file, err := bot.GetFileContext(ctx, NewFile(fileID))
+	if err != nil {
+		return err
+	}
+
+	return bot.DownloadFile(ctx, file, w)
+}
+
+// DownloadFile streams the contents of a file returned by GetFile into w.
+func (bot *BotAPI) DownloadFile(ctx context.Context, file File, w io.Writer) error {
+	req, err := http.NewRequest("GET", file.Link(bot.Token), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("tgbotapi: downloading %s: %s", file.FileID, res.Status)
+	}
+
+	_, err = io.Copy(w, res.Body)
+
+	return err
+}
+
 // GetUpdates fetches updates.
 // If a WebHook is set, this will not return any data!
 //
@@ -0,0 +1,15 @@
+package tgbotapi
+
+import "testing"
+
+func TestSendMediaGroupReplyMarkup(t *testing.T) {
+	config := NewMediaGroup(ChatID(1),
+		NewInputMediaPhoto(FileID("a")),
+		NewInputMediaPhoto(FileID("b")))
+	config.ReplyMarkup = ReplyKeyboardRemove{RemoveKeyboard: true}
+
+	// Rejected before anything is sent, so the bot needs no token.
+	if _, err := (&BotAPI{}).SendMediaGroup(config); err == nil {
+		t.Error("media group with reply markup was sent")
+	}
+}
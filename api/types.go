@@ -2,39 +2,123 @@ package tgbotapi
 
 import (
 	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
 )
 
 // APIResponse is a response from the Telegram API with the result stored raw.
 type APIResponse struct {
-	Ok          bool            `json:"ok"`
-	Result      json.RawMessage `json:"result"`
-	ErrorCode   int32             `json:"error_code"`
-	Description string          `json:"description"`
+	Ok          bool                `json:"ok"`
+	Result      json.RawMessage     `json:"result"`
+	ErrorCode   int32               `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  *ResponseParameters `json:"parameters"`
+}
+
+// ResponseParameters explains why a request failed, and what to do instead.
+type ResponseParameters struct {
+	MigrateToChatID int64 `json:"migrate_to_chat_id"`
+	RetryAfter      int32 `json:"retry_after"`
+}
+
+// Error is returned when Telegram refuses a request.
+type Error struct {
+	Code        int32
+	Description string
+
+	// Parameters, if set, explain how the request could succeed, such as
+	// how long to wait before retrying it.
+	Parameters *ResponseParameters
+}
+
+func (err *Error) Error() string {
+	return err.Description
+}
+
+// IsNotModified returns true if err is Telegram refusing to edit a message
+// because the edit would leave it unchanged.
+func IsNotModified(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return strings.Contains(apiErr.Description, "message is not modified")
+}
+
+// newError returns the error for a response which isn't ok.
+func (resp APIResponse) newError() error {
+	return &Error{
+		Code:        resp.ErrorCode,
+		Description: resp.Description,
+		Parameters:  resp.Parameters,
+	}
 }
 
 // Update is an update response, from GetUpdates.
 type Update struct {
-	UpdateID int32     `json:"update_id"`
-	Message  Message `json:"message"`
+	UpdateID      int32          `json:"update_id"`
+	Message       Message        `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query"`
+}
+
+// ChatRef refers to the recipient of a request, either by its numeric ID or,
+// for channels and supergroups, by its public @username.
+//
+// Supergroup and channel IDs don't fit in 32 bits, so IDs are int64.
+type ChatRef struct {
+	ID       int64
+	Username string
+}
+
+// ChatID refers to a chat by its numeric ID.
+func ChatID(id int64) ChatRef {
+	return ChatRef{ID: id}
+}
+
+// ChatUsername refers to a public channel or supergroup by its username,
+// with or without the leading @.
+func ChatUsername(username string) ChatRef {
+	return ChatRef{Username: strings.TrimPrefix(username, "@")}
+}
+
+// String returns the ref the way Telegram expects it in a chat_id parameter.
+func (ref ChatRef) String() string {
+	if ref.Username != "" {
+		return "@" + ref.Username
+	}
+
+	return strconv.FormatInt(ref.ID, 10)
+}
+
+// MarshalJSON encodes the ref as a chat_id field.
+func (ref ChatRef) MarshalJSON() ([]byte, error) {
+	if ref.Username != "" {
+		return json.Marshal("@" + ref.Username)
+	}
+
+	return json.Marshal(ref.ID)
 }
 
 // User is a user, contained in Message and returned by GetSelf.
 type User struct {
-	ID        int32    `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	UserName  string `json:"username"`
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	UserName     string `json:"username"`
+	LanguageCode string `json:"language_code"`
 }
 
 // GroupChat is a group chat, and not currently in use.
 type GroupChat struct {
-	ID    int32    `json:"id"`
+	ID    int64  `json:"id"`
 	Title string `json:"title"`
 }
 
 // UserOrGroupChat is returned in Message, because it's not clear which it is.
 type UserOrGroupChat struct {
-	ID        int32    `json:"id"`
+	ID        int64  `json:"id"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	UserName  string `json:"username"`
@@ -43,73 +127,146 @@ type UserOrGroupChat struct {
 
 // Message is returned by almost every request, and contains data about almost anything.
 type Message struct {
-	MessageID           int32             `json:"message_id"`
+	MessageID           int32           `json:"message_id"`
+	MessageThreadID     int32           `json:"message_thread_id"`
+	MediaGroupID        string          `json:"media_group_id"`
 	From                User            `json:"from"`
-	Date                int32             `json:"date"`
+	Date                int32           `json:"date"`
 	Chat                UserOrGroupChat `json:"chat"`
 	ForwardFrom         User            `json:"forward_from"`
-	ForwardDate         int32             `json:"forward_date"`
+	ForwardDate         int32           `json:"forward_date"`
 	ReplyToMessage      *Message        `json:"reply_to_message"`
 	Text                string          `json:"text"`
+	Entities            []MessageEntity `json:"entities"`
+	Caption             string          `json:"caption"`
+	CaptionEntities     []MessageEntity `json:"caption_entities"`
 	Audio               Audio           `json:"audio"`
 	Document            Document        `json:"document"`
 	Photo               []PhotoSize     `json:"photo"`
 	Sticker             Sticker         `json:"sticker"`
 	Video               Video           `json:"video"`
+	Voice               Voice           `json:"voice"`
+	Animation           Animation       `json:"animation"`
+	VideoNote           VideoNote       `json:"video_note"`
 	Contact             Contact         `json:"contact"`
 	Location            Location        `json:"location"`
+	Venue               Venue           `json:"venue"`
 	NewChatParticipant  User            `json:"new_chat_participant"`
 	LeftChatParticipant User            `json:"left_chat_participant"`
 	NewChatTitle        string          `json:"new_chat_title"`
 	NewChatPhoto        string          `json:"new_chat_photo"`
 	DeleteChatPhoto     bool            `json:"delete_chat_photo"`
 	GroupChatCreated    bool            `json:"group_chat_created"`
+
+	// UsersShared, ChatShared and WebAppData are sent by keyboard buttons
+	// which request users, a chat or open a Web App.
+	UsersShared *UsersShared `json:"users_shared"`
+	ChatShared  *ChatShared  `json:"chat_shared"`
+	WebAppData  *WebAppData  `json:"web_app_data"`
+
+	// ReplyMarkup is the inline keyboard attached to the message, if any.
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup"`
+}
+
+// MessageEntity marks part of a message's text or caption, such as a link or
+// bold text. Offset and Length are in UTF-16 code units.
+type MessageEntity struct {
+	Type          string `json:"type"`
+	Offset        int32  `json:"offset"`
+	Length        int32  `json:"length"`
+	URL           string `json:"url,omitempty"`
+	User          *User  `json:"user,omitempty"`
+	Language      string `json:"language,omitempty"`
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
 }
 
 // PhotoSize contains information about photos, including ID and Width and Height.
 type PhotoSize struct {
-	FileID   string `json:"file_id"`
-	Width    int32    `json:"width"`
-	Height   int32    `json:"height"`
-	FileSize int32    `json:"file_size"`
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int32  `json:"width"`
+	Height       int32  `json:"height"`
+	FileSize     int32  `json:"file_size"`
 }
 
 // Audio contains information about audio, including ID and Duration.
 type Audio struct {
-	FileID   string `json:"file_id"`
-	Duration int32    `json:"duration"`
-	MimeType string `json:"mime_type"`
-	FileSize int32    `json:"file_size"`
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	Duration     int32     `json:"duration"`
+	Performer    string    `json:"performer"`
+	Title        string    `json:"title"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	FileSize     int32     `json:"file_size"`
+	Thumbnail    PhotoSize `json:"thumbnail"`
 }
 
 // Document contains information about a document, including ID and a Thumbnail.
 type Document struct {
-	FileID    string    `json:"file_id"`
-	Thumbnail PhotoSize `json:"thumb"`
-	FileName  string    `json:"file_name"`
-	MimeType  string    `json:"mime_type"`
-	FileSize  int32       `json:"file_size"`
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	Thumbnail    PhotoSize `json:"thumbnail"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	FileSize     int32     `json:"file_size"`
 }
 
 // Sticker contains information about a sticker, including ID and Thumbnail.
 type Sticker struct {
-	FileID    string    `json:"file_id"`
-	Width     int32       `json:"width"`
-	Height    int32       `json:"height"`
-	Thumbnail PhotoSize `json:"thumb"`
-	FileSize  int32       `json:"file_size"`
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	Thumbnail    PhotoSize `json:"thumbnail"`
+	FileSize     int32     `json:"file_size"`
 }
 
 // Video contains information about a video, including ID and duration and Thumbnail.
 type Video struct {
-	FileID    string    `json:"file_id"`
-	Width     int32       `json:"width"`
-	Height    int32       `json:"height"`
-	Duration  int32       `json:"duration"`
-	Thumbnail PhotoSize `json:"thumb"`
-	MimeType  string    `json:"mime_type"`
-	FileSize  int32       `json:"file_size"`
-	Caption   string    `json:"caption"`
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	Duration     int32     `json:"duration"`
+	Thumbnail    PhotoSize `json:"thumbnail"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	FileSize     int32     `json:"file_size"`
+}
+
+// Voice contains information about a voice message, including ID and Duration.
+type Voice struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Duration     int32  `json:"duration"`
+	MimeType     string `json:"mime_type"`
+	FileSize     int32  `json:"file_size"`
+}
+
+// Animation contains information about a GIF or silent video, including ID
+// and Thumbnail. Messages with an Animation have it as their Document too.
+type Animation struct {
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	Duration     int32     `json:"duration"`
+	Thumbnail    PhotoSize `json:"thumbnail"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	FileSize     int32     `json:"file_size"`
+}
+
+// VideoNote contains information about a round video message, including ID
+// and its Length, the diameter of the video.
+type VideoNote struct {
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	Length       int32     `json:"length"`
+	Duration     int32     `json:"duration"`
+	Thumbnail    PhotoSize `json:"thumbnail"`
+	FileSize     int32     `json:"file_size"`
 }
 
 // Contact contains information about a contact, such as PhoneNumber and UserId.
@@ -126,28 +283,241 @@ type Location struct {
 	Latitude  float32 `json:"latitude"`
 }
 
+// UsersShared contains the users picked with a KeyboardButton's
+// RequestUsers, with the RequestID it asked for them with.
+type UsersShared struct {
+	RequestID int32        `json:"request_id"`
+	Users     []SharedUser `json:"users"`
+}
+
+// SharedUser is a user picked with a KeyboardButton. The names are only set
+// if the button requested them.
+type SharedUser struct {
+	UserID    int64  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	UserName  string `json:"username"`
+}
+
+// ChatShared contains the chat picked with a KeyboardButton's RequestChat,
+// with the RequestID it asked for it with. Title and UserName are only set
+// if the button requested them.
+type ChatShared struct {
+	RequestID int32  `json:"request_id"`
+	ChatID    int64  `json:"chat_id"`
+	Title     string `json:"title"`
+	UserName  string `json:"username"`
+}
+
+// WebAppData is data sent by a Web App opened from a KeyboardButton.
+type WebAppData struct {
+	Data       string `json:"data"`
+	ButtonText string `json:"button_text"`
+}
+
+// Venue contains information about a named place, such as its Title and Address.
+type Venue struct {
+	Location     Location `json:"location"`
+	Title        string   `json:"title"`
+	Address      string   `json:"address"`
+	FoursquareID string   `json:"foursquare_id"`
+}
+
+// File is a file ready to be downloaded, as returned by GetFile.
+// The download link is valid for at least an hour.
+type File struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileSize     int32  `json:"file_size"`
+	FilePath     string `json:"file_path"`
+}
+
+// Link returns the URL to download the file from.
+func (file File) Link(token string) string {
+	return "https://api.telegram.org/file/bot" + token + "/" + file.FilePath
+}
+
 // UserProfilePhotos contains information a set of user profile photos.
 type UserProfilePhotos struct {
-	TotalCount int32         `json:"total_count"`
+	TotalCount int32       `json:"total_count"`
 	Photos     []PhotoSize `json:"photos"`
 }
 
+// ReplyMarkup is implemented by the types which can be attached to a message
+// as its reply_markup: ReplyKeyboardMarkup, InlineKeyboardMarkup,
+// ReplyKeyboardRemove and ForceReply.
+type ReplyMarkup interface {
+	replyMarkup()
+}
+
 // ReplyKeyboardMarkup allows the Bot to set a custom keyboard.
 type ReplyKeyboardMarkup struct {
-	Keyboard        [][]string `json:"keyboard"`
-	ResizeKeyboard  bool       `json:"resize_keyboard"`
-	OneTimeKeyboard bool       `json:"one_time_keyboard"`
-	Selective       bool       `json:"selective"`
+	Keyboard              [][]KeyboardButton `json:"keyboard"`
+	IsPersistent          bool               `json:"is_persistent,omitempty"`
+	ResizeKeyboard        bool               `json:"resize_keyboard,omitempty"`
+	OneTimeKeyboard       bool               `json:"one_time_keyboard,omitempty"`
+	InputFieldPlaceholder string             `json:"input_field_placeholder,omitempty"`
+	Selective             bool               `json:"selective,omitempty"`
+}
+
+// KeyboardButton is a single button of a ReplyKeyboardMarkup, which sends
+// Text as a message when pressed. At most one of the other fields may be set,
+// to send something else instead.
+type KeyboardButton struct {
+	Text            string                      `json:"text"`
+	RequestUsers    *KeyboardButtonRequestUsers `json:"request_users,omitempty"`
+	RequestChat     *KeyboardButtonRequestChat  `json:"request_chat,omitempty"`
+	RequestContact  bool                        `json:"request_contact,omitempty"`
+	RequestLocation bool                        `json:"request_location,omitempty"`
+	RequestPoll     *KeyboardButtonPollType     `json:"request_poll,omitempty"`
+	WebApp          *WebAppInfo                 `json:"web_app,omitempty"`
+}
+
+// KeyboardButtonRequestUsers has the user pick users to share with the bot,
+// which are sent back as a Message's UsersShared with the same RequestID.
+// UserIsBot and UserIsPremium only allow users which match, if set.
+type KeyboardButtonRequestUsers struct {
+	RequestID       int32 `json:"request_id"`
+	UserIsBot       *bool `json:"user_is_bot,omitempty"`
+	UserIsPremium   *bool `json:"user_is_premium,omitempty"`
+	MaxQuantity     int32 `json:"max_quantity,omitempty"`
+	RequestName     bool  `json:"request_name,omitempty"`
+	RequestUsername bool  `json:"request_username,omitempty"`
+	RequestPhoto    bool  `json:"request_photo,omitempty"`
+}
+
+// KeyboardButtonRequestChat has the user pick a chat to share with the bot,
+// which is sent back as a Message's ChatShared with the same RequestID.
+// ChatIsForum and ChatHasUsername only allow chats which match, if set.
+type KeyboardButtonRequestChat struct {
+	RequestID               int32                    `json:"request_id"`
+	ChatIsChannel           bool                     `json:"chat_is_channel"`
+	ChatIsForum             *bool                    `json:"chat_is_forum,omitempty"`
+	ChatHasUsername         *bool                    `json:"chat_has_username,omitempty"`
+	ChatIsCreated           bool                     `json:"chat_is_created,omitempty"`
+	UserAdministratorRights *ChatAdministratorRights `json:"user_administrator_rights,omitempty"`
+	BotAdministratorRights  *ChatAdministratorRights `json:"bot_administrator_rights,omitempty"`
+	BotIsMember             bool                     `json:"bot_is_member,omitempty"`
+	RequestTitle            bool                     `json:"request_title,omitempty"`
+	RequestUsername         bool                     `json:"request_username,omitempty"`
+	RequestPhoto            bool                     `json:"request_photo,omitempty"`
+}
+
+// ChatAdministratorRights are the rights of an administrator in a chat.
+type ChatAdministratorRights struct {
+	IsAnonymous         bool `json:"is_anonymous"`
+	CanManageChat       bool `json:"can_manage_chat"`
+	CanDeleteMessages   bool `json:"can_delete_messages"`
+	CanManageVideoChats bool `json:"can_manage_video_chats"`
+	CanRestrictMembers  bool `json:"can_restrict_members"`
+	CanPromoteMembers   bool `json:"can_promote_members"`
+	CanChangeInfo       bool `json:"can_change_info"`
+	CanInviteUsers      bool `json:"can_invite_users"`
+	CanPostStories      bool `json:"can_post_stories"`
+	CanEditStories      bool `json:"can_edit_stories"`
+	CanDeleteStories    bool `json:"can_delete_stories"`
+	CanPostMessages     bool `json:"can_post_messages,omitempty"`
+	CanEditMessages     bool `json:"can_edit_messages,omitempty"`
+	CanPinMessages      bool `json:"can_pin_messages,omitempty"`
+	CanManageTopics     bool `json:"can_manage_topics,omitempty"`
 }
 
-// ReplyKeyboardHide allows the Bot to hide a custom keyboard.
-type ReplyKeyboardHide struct {
-	HideKeyboard bool `json:"hide_keyboard"`
-	Selective    bool `json:"selective"`
+// KeyboardButtonPollType has the user create a poll and send it to the chat.
+// Type is "quiz" or "regular" to only allow that kind, or empty for either.
+type KeyboardButtonPollType struct {
+	Type string `json:"type,omitempty"`
+}
+
+// WebAppInfo is a Web App to open, at URL.
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// InlineKeyboardMarkup allows the Bot to attach buttons to the message itself.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton is a single button of an InlineKeyboardMarkup. Exactly
+// one of the fields other than Text must be set.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+
+	// SwitchInlineQuery has the user pick a chat, and starts an inline
+	// query of the bot there with the given text, which may be empty.
+	SwitchInlineQuery *string `json:"switch_inline_query,omitempty"`
+	// SwitchInlineQueryCurrentChat is SwitchInlineQuery in the same chat.
+	SwitchInlineQueryCurrentChat *string `json:"switch_inline_query_current_chat,omitempty"`
+
+	CopyText *CopyTextButton `json:"copy_text,omitempty"`
+	LoginURL *LoginURL       `json:"login_url,omitempty"`
+}
+
+// CopyTextButton copies Text to the clipboard when pressed.
+type CopyTextButton struct {
+	Text string `json:"text"`
+}
+
+// LoginURL logs the user in to a website with their Telegram account when
+// pressed, through the Telegram Login Widget.
+type LoginURL struct {
+	URL                string `json:"url"`
+	ForwardText        string `json:"forward_text,omitempty"`
+	BotUsername        string `json:"bot_username,omitempty"`
+	RequestWriteAccess bool   `json:"request_write_access,omitempty"`
+}
+
+// CallbackQuery is sent when a user presses an inline keyboard button with
+// CallbackData. Message is the message with the button, unless it was sent
+// through inline mode, when InlineMessageID is set instead.
+type CallbackQuery struct {
+	ID              string   `json:"id"`
+	From            User     `json:"from"`
+	Message         *Message `json:"message"`
+	InlineMessageID string   `json:"inline_message_id"`
+	ChatInstance    string   `json:"chat_instance"`
+	Data            string   `json:"data"`
+	GameShortName   string   `json:"game_short_name"`
+}
+
+// ReplyKeyboardRemove allows the Bot to remove a custom keyboard.
+// RemoveKeyboard must be true.
+type ReplyKeyboardRemove struct {
+	RemoveKeyboard bool `json:"remove_keyboard"`
+	Selective      bool `json:"selective,omitempty"`
 }
 
 // ForceReply allows the Bot to have users directly reply to it without additional interaction.
+// ForceReply must be true.
 type ForceReply struct {
-	ForceReply bool `json:"force_reply"`
-	Selective  bool `json:"force_reply"`
+	ForceReply            bool   `json:"force_reply"`
+	InputFieldPlaceholder string `json:"input_field_placeholder,omitempty"`
+	Selective             bool   `json:"selective,omitempty"`
+}
+
+func (ReplyKeyboardMarkup) replyMarkup()  {}
+func (InlineKeyboardMarkup) replyMarkup() {}
+func (ReplyKeyboardRemove) replyMarkup()  {}
+func (ForceReply) replyMarkup()           {}
+
+// ReplyParameters describes the message being replied to, and optionally
+// the part of it to quote.
+type ReplyParameters struct {
+	MessageID                int32    `json:"message_id"`
+	ChatID                   *ChatRef `json:"chat_id,omitempty"`
+	AllowSendingWithoutReply bool     `json:"allow_sending_without_reply,omitempty"`
+	Quote                    string   `json:"quote,omitempty"`
+	QuoteParseMode           string   `json:"quote_parse_mode,omitempty"`
+	QuotePosition            int32    `json:"quote_position,omitempty"`
+}
+
+// LinkPreviewOptions controls how the link preview of a text message is generated.
+type LinkPreviewOptions struct {
+	IsDisabled       bool   `json:"is_disabled,omitempty"`
+	URL              string `json:"url,omitempty"`
+	PreferSmallMedia bool   `json:"prefer_small_media,omitempty"`
+	PreferLargeMedia bool   `json:"prefer_large_media,omitempty"`
+	ShowAboveText    bool   `json:"show_above_text,omitempty"`
 }
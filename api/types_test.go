@@ -0,0 +1,114 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestReplyMarkupJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		markup ReplyMarkup
+		want   string
+	}{
+		{
+			name: "reply keyboard",
+			markup: ReplyKeyboardMarkup{
+				Keyboard:        [][]KeyboardButton{{{Text: "yes"}, {Text: "no"}}},
+				OneTimeKeyboard: true,
+			},
+			want: `{"keyboard":[[{"text":"yes"},{"text":"no"}]],"one_time_keyboard":true}`,
+		},
+		{
+			name: "reply keyboard requests",
+			markup: ReplyKeyboardMarkup{
+				Keyboard: [][]KeyboardButton{{
+					{Text: "Share number", RequestContact: true},
+					{Text: "Pick a user", RequestUsers: &KeyboardButtonRequestUsers{RequestID: 1}},
+				}},
+				IsPersistent:          true,
+				InputFieldPlaceholder: "Choose",
+			},
+			want: `{"keyboard":[[{"text":"Share number","request_contact":true},{"text":"Pick a user","request_users":{"request_id":1}}]],"is_persistent":true,"input_field_placeholder":"Choose"}`,
+		},
+		{
+			name: "inline keyboard",
+			markup: InlineKeyboardMarkup{
+				InlineKeyboard: [][]InlineKeyboardButton{{
+					{Text: "open", URL: "https://telegram.org"},
+					{Text: "click", CallbackData: "clicked"},
+				}},
+			},
+			want: `{"inline_keyboard":[[{"text":"open","url":"https://telegram.org"},{"text":"click","callback_data":"clicked"}]]}`,
+		},
+		{
+			name:   "remove keyboard",
+			markup: ReplyKeyboardRemove{RemoveKeyboard: true, Selective: true},
+			want:   `{"remove_keyboard":true,"selective":true}`,
+		},
+		{
+			name:   "force reply",
+			markup: ForceReply{ForceReply: true, Selective: true},
+			want:   `{"force_reply":true,"selective":true}`,
+		},
+		{
+			name:   "force reply placeholder",
+			markup: &ForceReply{ForceReply: true, InputFieldPlaceholder: "Your name"},
+			want:   `{"force_reply":true,"input_field_placeholder":"Your name"}`,
+		},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.markup)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if string(data) != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, data, test.want)
+		}
+	}
+}
+
+func TestSendOptionsReplyMarkup(t *testing.T) {
+	opts := SendOptions{
+		ReplyMarkup: ForceReply{ForceReply: true, Selective: true},
+	}
+
+	v := url.Values{}
+	if err := opts.Values(v); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := v.Get("reply_markup"), `{"force_reply":true,"selective":true}`; got != want {
+		t.Errorf("reply_markup: got %s, want %s", got, want)
+	}
+}
+
+func TestSharedIDs(t *testing.T) {
+	// Supergroup IDs don't fit in 32 bits, and Telegram warns user IDs
+	// may not either.
+	var msg Message
+	err := json.Unmarshal([]byte(`{
+		"message_id": 1,
+		"chat": {"id": -1001234567890, "type": "supergroup"},
+		"from": {"id": 8765432109, "first_name": "Ann"},
+		"users_shared": {"request_id": 1, "users": [{"user_id": 9876543210}]},
+		"chat_shared": {"request_id": 2, "chat_id": -1009876543210}
+	}`), &msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := msg.From.ID; got != 8765432109 {
+		t.Errorf("from: got %d", got)
+	}
+	if got := msg.UsersShared.Users[0].UserID; got != 9876543210 {
+		t.Errorf("user_id: got %d", got)
+	}
+	if got := msg.ChatShared.ChatID; got != -1009876543210 {
+		t.Errorf("chat_id: got %d", got)
+	}
+}
@@ -0,0 +1,418 @@
+package tgbotapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileIDStore holds the FileIDs remembered by an UploadCache. Implement it to
+// keep them somewhere which survives restarts, or use JSONFileIDStore.
+type FileIDStore interface {
+	Get(key string) (string, bool)
+	Set(key, fileID string) error
+	Delete(key string) error
+}
+
+// UploadCache remembers the FileID Telegram gives each file uploaded through
+// it, keyed by a hash of the file's contents and, for files on disk, by their
+// path and modification time. Sending the same file again then reuses the
+// FileID instead of uploading the file again. FileIDs only work for the bot
+// they were given to, so each bot's are kept apart, and one cache can be
+// shared between several bots.
+//
+// Set BotAPI.UploadCache to have every Send method consult it.
+type UploadCache struct {
+	Store FileIDStore
+}
+
+// NewUploadCache creates an UploadCache, keeping FileIDs in store, or in
+// memory if store is nil.
+func NewUploadCache(store FileIDStore) *UploadCache {
+	if store == nil {
+		store = NewMemoryFileIDStore()
+	}
+
+	return &UploadCache{
+		Store: store,
+	}
+}
+
+// cacheLookup tracks the files of a request through the cache.
+type cacheLookup struct {
+	cache *UploadCache
+
+	// prefix keeps the keys of each bot apart in the store.
+	prefix string
+	// debug logs the store failing, which otherwise only costs a re-upload.
+	debug bool
+
+	// files is what to send: the original files, with those found in the
+	// cache replaced by their FileIDs.
+	files map[string]InputFile
+	// keys are the cache keys of each file, by parameter name.
+	keys map[string][]string
+	// hashes are filled in as readers which couldn't be hashed beforehand
+	// are uploaded.
+	hashes map[string]hash.Hash
+	// hits are the parameters which were found in the cache.
+	hits []string
+}
+
+// lookup finds the files of a request made by bot in the cache.
+func (cache *UploadCache) lookup(bot *BotAPI, files map[string]InputFile) *cacheLookup {
+	// The part of the token before the colon is the bot's ID, which, unlike
+	// the rest, is fine to keep in the store.
+	botID := strings.SplitN(bot.Token, ":", 2)[0]
+
+	lookup := &cacheLookup{
+		cache:  cache,
+		prefix: "bot" + botID + ":",
+		debug:  bot.Debug,
+		files:  make(map[string]InputFile),
+		keys:   make(map[string][]string),
+		hashes: make(map[string]hash.Hash),
+	}
+
+	for fieldname, file := range files {
+		lookup.files[fieldname] = file
+
+		if !file.NeedsUpload() || fieldname == "thumbnail" {
+			continue
+		}
+
+		// Files on disk are looked up by their path first, which saves
+		// reading them when they haven't changed.
+		if key, err := file.pathKey(fieldname); err == nil && key != "" {
+			if fileID, ok := lookup.get(key); ok {
+				lookup.files[fieldname] = FileID(fileID)
+				lookup.keys[fieldname] = []string{key}
+				lookup.hits = append(lookup.hits, fieldname)
+				continue
+			}
+		}
+
+		keys, err := file.cacheKeys(fieldname)
+		if err != nil {
+			continue
+		}
+
+		lookup.keys[fieldname] = keys
+		if len(keys) == 0 {
+			h := sha256.New()
+			lookup.hashes[fieldname] = h
+			lookup.files[fieldname] = FileReader(file.name, io.TeeReader(file.reader, h))
+			continue
+		}
+
+		for _, key := range keys {
+			if fileID, ok := lookup.get(key); ok {
+				lookup.files[fieldname] = FileID(fileID)
+				lookup.hits = append(lookup.hits, fieldname)
+
+				// Remember the file under its other keys too, such as
+				// the path of a copy of a file sent before.
+				for _, other := range keys {
+					if other != key {
+						lookup.set(other, fileID)
+					}
+				}
+				break
+			}
+		}
+	}
+
+	return lookup
+}
+
+// forget removes the cached FileIDs which were used, after Telegram refused
+// one of them, and returns true if the request can be made again without
+// them.
+func (lookup *cacheLookup) forget(files map[string]InputFile) bool {
+	for _, fieldname := range lookup.hits {
+		for _, key := range lookup.keys[fieldname] {
+			if err := lookup.cache.Store.Delete(lookup.prefix + key); err != nil && lookup.debug {
+				log.Printf("upload cache: can't forget %s: %v\n", key, err)
+			}
+		}
+	}
+
+	for fieldname, file := range files {
+		if file.reader != nil && lookup.files[fieldname].NeedsUpload() {
+			return false
+		}
+	}
+
+	return len(lookup.hits) > 0
+}
+
+// remember stores the FileIDs of the files uploaded in message.
+func (lookup *cacheLookup) remember(message Message) {
+	for fieldname, keys := range lookup.keys {
+		if !lookup.files[fieldname].NeedsUpload() {
+			continue
+		}
+
+		fileID := message.fileIDFor(fieldname)
+		if fileID == "" {
+			continue
+		}
+
+		if h, ok := lookup.hashes[fieldname]; ok {
+			keys = []string{contentKey(fieldname, h)}
+		}
+
+		for _, key := range keys {
+			lookup.set(key, fileID)
+		}
+	}
+}
+
+func (lookup *cacheLookup) get(key string) (string, bool) {
+	return lookup.cache.Store.Get(lookup.prefix + key)
+}
+
+func (lookup *cacheLookup) set(key, fileID string) {
+	if err := lookup.cache.Store.Set(lookup.prefix+key, fileID); err != nil && lookup.debug {
+		log.Printf("upload cache: can't remember %s: %v\n", key, err)
+	}
+}
+
+// isFileIDRejection returns true if err is Telegram refusing a FileID, such
+// as one which has expired, rather than the request failing for some other
+// reason. Telegram describes these both as "wrong file identifier" and as
+// FILE_REFERENCE_EXPIRED and the like.
+func isFileIDRejection(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	description := strings.ToLower(apiErr.Description)
+
+	for _, rejection := range []string{"file identifier", "file reference", "file_reference"} {
+		if strings.Contains(description, rejection) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheKeys returns the keys the file is cached under when sent as
+// fieldname. Readers which can't be hashed without consuming them have none.
+func (file InputFile) cacheKeys(fieldname string) ([]string, error) {
+	h := sha256.New()
+
+	switch {
+	case file.path != "":
+		key, err := file.pathKey(fieldname)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := os.Open(file.path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(h, f); err != nil {
+			return nil, err
+		}
+
+		return []string{key, contentKey(fieldname, h)}, nil
+
+	case file.data != nil:
+		h.Write(file.data)
+
+		return []string{contentKey(fieldname, h)}, nil
+	}
+
+	seeker, ok := file.reader.(io.ReadSeeker)
+	if !ok {
+		return nil, nil
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(h, seeker)
+	if _, seekErr := seeker.Seek(offset, io.SeekStart); err == nil {
+		err = seekErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{contentKey(fieldname, h)}, nil
+}
+
+// pathKey returns the key a file on disk is cached under when sent as
+// fieldname, which changes whenever the file is modified.
+func (file InputFile) pathKey(fieldname string) (string, error) {
+	if file.path == "" {
+		return "", nil
+	}
+
+	path, err := filepath.Abs(file.path)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	return fieldname + ":path:" + path + ":" + strconv.FormatInt(info.ModTime().UnixNano(), 10), nil
+}
+
+func contentKey(fieldname string, h hash.Hash) string {
+	return fieldname + ":sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// fileIDFor returns the FileID of the file sent as fieldname in the message.
+func (message Message) fileIDFor(fieldname string) string {
+	switch fieldname {
+	case "photo":
+		var top PhotoSize
+		for _, photo := range message.Photo {
+			if photo.Width*photo.Height > top.Width*top.Height {
+				top = photo
+			}
+		}
+
+		return top.FileID
+	case "audio":
+		return message.Audio.FileID
+	case "document":
+		return message.Document.FileID
+	case "sticker":
+		return message.Sticker.FileID
+	case "video":
+		return message.Video.FileID
+	case "voice":
+		return message.Voice.FileID
+	case "animation":
+		return message.Animation.FileID
+	case "video_note":
+		return message.VideoNote.FileID
+	}
+
+	return ""
+}
+
+// MemoryFileIDStore is a FileIDStore which only lasts as long as the process.
+type MemoryFileIDStore struct {
+	lock    sync.Mutex
+	fileIDs map[string]string
+}
+
+// NewMemoryFileIDStore creates an empty MemoryFileIDStore.
+func NewMemoryFileIDStore() *MemoryFileIDStore {
+	return &MemoryFileIDStore{
+		fileIDs: make(map[string]string),
+	}
+}
+
+func (store *MemoryFileIDStore) Get(key string) (string, bool) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	fileID, ok := store.fileIDs[key]
+	return fileID, ok
+}
+
+func (store *MemoryFileIDStore) Set(key, fileID string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.fileIDs[key] = fileID
+	return nil
+}
+
+func (store *MemoryFileIDStore) Delete(key string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.fileIDs, key)
+	return nil
+}
+
+// JSONFileIDStore is a FileIDStore kept in a JSON file, so the cache survives
+// restarts. The file is rewritten on every change.
+type JSONFileIDStore struct {
+	MemoryFileIDStore
+
+	path string
+}
+
+// NewJSONFileIDStore opens the JSONFileIDStore at path, which is created on
+// the first change if it doesn't exist.
+func NewJSONFileIDStore(path string) (*JSONFileIDStore, error) {
+	store := &JSONFileIDStore{
+		MemoryFileIDStore: MemoryFileIDStore{
+			fileIDs: make(map[string]string),
+		},
+
+		path: path,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.fileIDs); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (store *JSONFileIDStore) Set(key, fileID string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.fileIDs[key] = fileID
+	return store.save()
+}
+
+func (store *JSONFileIDStore) Delete(key string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.fileIDs, key)
+	return store.save()
+}
+
+// save writes the store out, replacing the old file only once the new one
+// is complete.
+func (store *JSONFileIDStore) save() error {
+	data, err := json.Marshal(store.fileIDs)
+	if err != nil {
+		return err
+	}
+
+	tmp := store.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, store.path)
+}
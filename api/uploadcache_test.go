@@ -0,0 +1,18 @@
+package tgbotapi
+
+import "testing"
+
+func TestFileIDRejection(t *testing.T) {
+	for description, want := range map[string]bool{
+		"Bad Request: wrong file identifier/HTTP URL specified": true,
+		"Bad Request: FILE_REFERENCE_EXPIRED":                   true,
+		"Bad Request: FILE_REFERENCE_INVALID":                   true,
+		"Bad Request: wrong remote file identifier specified":   true,
+		"Bad Request: message is too long":                      false,
+		"Too Many Requests: retry after 5":                      false,
+	} {
+		if got := isFileIDRejection(&Error{Description: description}); got != want {
+			t.Errorf("%q: got %v, want %v", description, got, want)
+		}
+	}
+}
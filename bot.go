@@ -1,7 +1,11 @@
 package telegram
 
 import (
+	"time"
+
 	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/callbackdata"
+	"github.com/AmandaCameron/go-telegram/mediacache"
 )
 
 // Bot represents and holds the meta information for a Telegram Bot.
@@ -11,7 +15,25 @@ type Bot struct {
 	Help       string
 	LastUpdate int32
 
-	Commands Commands
+	// AlbumQuietPeriod is how long MessagesChan waits for more of an album
+	// after each part of it arrives, before delivering the album as one
+	// message. Zero delivers each part of an album separately.
+	AlbumQuietPeriod time.Duration
+
+	Commands  Commands
+	Callbacks CallbackRouter
+	Menus     MenuRouter
+
+	// CallbackCodec encodes the payloads of buttons made with
+	// CallbackButton. By default it has a random secret, so buttons stop
+	// working when the bot restarts; set one with a fixed secret, and a
+	// shared Store if needed, to keep them working.
+	CallbackCodec *callbackdata.Codec
+
+	// MediaCache, if set, keeps the files opened with Message.Open on disk.
+	MediaCache *mediacache.Cache
+
+	deletions *deletionQueue
 }
 
 // Sendable means you can use this to send a message or file to a user.
@@ -35,7 +57,13 @@ func NewBot(token string) (*Bot, error) {
 	bot := &Bot{
 		api: b,
 
+		AlbumQuietPeriod: time.Second,
+
 		Commands: Commands{},
+
+		CallbackCodec: callbackdata.New(nil, callbackdata.NewMemoryStore()),
+
+		deletions: newDeletionQueue(b),
 	}
 
 	bot.AddCommand(helpCommand)
@@ -53,12 +81,21 @@ func (bot *Bot) HandleCommand(msg Message) bool {
 	return bot.Commands.Handle(msg)
 }
 
+// API returns the underlying Bot API client, for use with packages such as
+// mediacache.
+func (bot *Bot) API() *tgbotapi.BotAPI {
+	return bot.api
+}
+
 // SendTyping sends a message saying that the bot is typing a message.
-func (bot *Bot) SendTyping(chatId int32) {
-	bot.api.SendChatAction(tgbotapi.NewChatAction(chatId, tgbotapi.ChatTyping))
+func (bot *Bot) SendTyping(chat tgbotapi.ChatRef) {
+	bot.api.SendChatAction(tgbotapi.NewChatAction(chat, tgbotapi.ChatTyping))
 }
 
-// GetMessages returns the current messages from the Bot API.
+// GetMessages returns the current messages from the Bot API. Each part of an
+// album is returned as a separate message. Presses of inline keyboard buttons
+// are passed to DispatchCallback, and presses of reply keyboard buttons with
+// handlers to DispatchButton, rather than returned.
 func (bot *Bot) GetMessages() ([]Message, error) {
 	updates, err := bot.api.GetUpdates(tgbotapi.UpdateConfig{
 		Offset: bot.LastUpdate,
@@ -76,22 +113,37 @@ func (bot *Bot) GetMessages() ([]Message, error) {
 		}
 		bot.LastUpdate = update.UpdateID
 
-		ret = append(ret, Message{
+		if update.CallbackQuery != nil {
+			bot.DispatchCallback(*update.CallbackQuery)
+			continue
+		}
+
+		msg := Message{
 			Message: update.Message,
 
 			context: make(map[string]interface{}),
 			bot:     bot,
 			dir:     incoming,
-		})
+		}
+
+		if bot.DispatchButton(msg) {
+			continue
+		}
+
+		ret = append(ret, msg)
 	}
 
 	return ret, nil
 }
 
 // MessagesChan returns a channel that will recieve messages periodically from
-// the bot's API endpoint.
+// the bot's API endpoint. The parts of an album are gathered into a single
+// message; see AlbumQuietPeriod and Message.AlbumItems.
 func (bot *Bot) MessagesChan() (chan Message, error) {
 	msgChan := make(chan Message, 100)
+	albums := newAlbumCollector(bot.AlbumQuietPeriod, func(msg Message) {
+		msgChan <- msg
+	})
 
 	go func() {
 		for {
@@ -104,7 +156,9 @@ func (bot *Bot) MessagesChan() (chan Message, error) {
 			}
 
 			for _, msg := range msgs {
-				msgChan <- msg
+				if !albums.add(msg) {
+					msgChan <- msg
+				}
 			}
 		}
 	}()
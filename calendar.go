@@ -0,0 +1,316 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/keyboard"
+)
+
+// noopData follows a widget's prefix in the data of buttons which do
+// nothing, such as headings and blank days. Encoded payloads never start
+// with it.
+const noopData = "-"
+
+// CalendarLocale holds the names a calendar is shown with.
+type CalendarLocale struct {
+	// Months are the names of the months, from January.
+	Months [12]string
+
+	// Weekdays are the short names of the days of the week, from Sunday.
+	Weekdays [7]string
+
+	// FirstDay is the day weeks start on.
+	FirstDay time.Weekday
+}
+
+// CalendarLocales are the locales calendars can be shown in, by language
+// code, such as "de" or "pt-BR". Calendars in other languages are shown in
+// English. Add to it before the bot starts handling messages.
+var CalendarLocales = map[string]CalendarLocale{
+	"en": {
+		Months:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		Weekdays: [7]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"},
+		FirstDay: time.Sunday,
+	},
+	"en-GB": {
+		Months:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		Weekdays: [7]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"},
+		FirstDay: time.Monday,
+	},
+	"de": {
+		Months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		Weekdays: [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		FirstDay: time.Monday,
+	},
+	"es": {
+		Months:   [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		Weekdays: [7]string{"do", "lu", "ma", "mi", "ju", "vi", "sá"},
+		FirstDay: time.Monday,
+	},
+	"fr": {
+		Months:   [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		Weekdays: [7]string{"di", "lu", "ma", "me", "je", "ve", "sa"},
+		FirstDay: time.Monday,
+	},
+	"it": {
+		Months:   [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		Weekdays: [7]string{"do", "lu", "ma", "me", "gi", "ve", "sa"},
+		FirstDay: time.Monday,
+	},
+	"nl": {
+		Months:   [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		Weekdays: [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		FirstDay: time.Monday,
+	},
+	"pt": {
+		Months:   [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		Weekdays: [7]string{"D", "S", "T", "Q", "Q", "S", "S"},
+		FirstDay: time.Sunday,
+	},
+	"ru": {
+		Months:   [12]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь", "Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"},
+		Weekdays: [7]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+		FirstDay: time.Monday,
+	},
+}
+
+// calendarLocale returns the locale for a language code, falling back to
+// the language without its region, then to English.
+func calendarLocale(code string) CalendarLocale {
+	if locale, ok := CalendarLocales[code]; ok {
+		return locale
+	}
+
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		if locale, ok := CalendarLocales[code[:i]]; ok {
+			return locale
+		}
+	}
+
+	return CalendarLocales["en"]
+}
+
+// Calendar is an inline keyboard showing a month at a time, from which the
+// user picks a date. Create one with Bot.Calendar.
+type Calendar struct {
+	bot     *Bot
+	prefix  string
+	handler func(cb *Callback, date time.Time)
+
+	// Text is the text of the message with the calendar.
+	Text string
+
+	// Min and Max are the first and last dates which can be picked, if
+	// they are set.
+	Min, Max time.Time
+
+	// Location is the time zone of the dates picked, midnight at the start
+	// of the day.
+	Location *time.Location
+
+	// Locale is the language code the calendar is shown in. By default it
+	// is the language of the user's Telegram app.
+	Locale string
+}
+
+// calendarState is the payload of a calendar's buttons. Day is 0 for
+// buttons which show Month rather than picking a day of it.
+type calendarState struct {
+	Month string
+	Day   int
+}
+
+// Calendar creates a calendar which passes the dates picked from it to
+// handler, and registers the handler for its buttons. name must be unique
+// among the bot's calendars. The handler decides what becomes of the
+// calendar, such as removing it with Callback.EditMarkup or replacing it
+// with a TimePicker.
+func (bot *Bot) Calendar(name string, handler func(cb *Callback, date time.Time)) *Calendar {
+	cal := &Calendar{
+		bot:     bot,
+		prefix:  "cal:" + name + ":",
+		handler: handler,
+
+		Text:     "Pick a date:",
+		Location: time.Local,
+	}
+
+	bot.HandleCallbackData(cal.prefix, calendarState{}, cal.press)
+	bot.HandleCallback(cal.prefix+noopData, func(*Callback) {})
+
+	return cal
+}
+
+// Show replies to msg with the calendar, showing the month of month, or the
+// current month if it is the zero time.
+func (cal *Calendar) Show(msg Message, month time.Time) error {
+	markup, err := cal.render(month, msg.From.LanguageCode)
+	if err != nil {
+		return err
+	}
+
+	reply := msg.ReplyWith("%s", cal.Text)
+	if reply == nil {
+		reply = cal.bot.Message(msg.chatRef(), "%s", cal.Text)
+	}
+
+	return reply.WithMarkup(markup).Send()
+}
+
+// Replace edits the message with the button cb is a press of to show the
+// calendar instead, at the month of month.
+func (cal *Calendar) Replace(cb *Callback, month time.Time) error {
+	markup, err := cal.render(month, cb.From.LanguageCode)
+	if err != nil {
+		return err
+	}
+
+	return cb.show(cal.Text, markup)
+}
+
+// press handles the calendar's buttons.
+func (cal *Calendar) press(cb *Callback, payload interface{}) {
+	state := payload.(*calendarState)
+
+	month, err := time.ParseInLocation("2006-01", state.Month, cal.Location)
+	if err != nil {
+		cb.Alert("This button has expired.")
+		return
+	}
+
+	if state.Day != 0 {
+		date := month.AddDate(0, 0, state.Day-1)
+		if cal.allowed(date) {
+			cal.handler(cb, date)
+			return
+		}
+
+		// Min or Max have moved since the calendar was shown.
+		cb.Answer("That date can't be picked any more.")
+	}
+
+	if err := cal.Replace(cb, month); err != nil {
+		cb.fail(err)
+	}
+}
+
+// day returns midnight at the start of t's day, in the calendar's location.
+func (cal *Calendar) day(t time.Time) time.Time {
+	year, month, day := t.In(cal.Location).Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, cal.Location)
+}
+
+// monthOf returns midnight at the start of the first day of t's month, in
+// the calendar's location.
+func (cal *Calendar) monthOf(t time.Time) time.Time {
+	year, month, _ := t.In(cal.Location).Date()
+
+	return time.Date(year, month, 1, 0, 0, 0, 0, cal.Location)
+}
+
+// allowed returns true if date is between Min and Max.
+func (cal *Calendar) allowed(date time.Time) bool {
+	if !cal.Min.IsZero() && date.Before(cal.day(cal.Min)) {
+		return false
+	}
+
+	return cal.Max.IsZero() || !date.After(cal.day(cal.Max))
+}
+
+// render returns the keyboard for a month, kept between the months of Min
+// and Max.
+func (cal *Calendar) render(month time.Time, language string) (*tgbotapi.InlineKeyboardMarkup, error) {
+	if month.IsZero() {
+		month = time.Now()
+	}
+
+	first := cal.monthOf(month)
+	if !cal.Min.IsZero() && first.Before(cal.monthOf(cal.Min)) {
+		first = cal.monthOf(cal.Min)
+	}
+	if !cal.Max.IsZero() && first.After(cal.monthOf(cal.Max)) {
+		first = cal.monthOf(cal.Max)
+	}
+
+	locale := calendarLocale(language)
+	if cal.Locale != "" {
+		locale = calendarLocale(cal.Locale)
+	}
+
+	prev, next := first.AddDate(0, -1, 0), first.AddDate(0, 1, 0)
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if cal.allowed(first.AddDate(0, 0, -1)) {
+		button, err := cal.button("‹", prev, 0)
+		if err != nil {
+			return nil, err
+		}
+		nav = append(nav, button)
+	}
+
+	nav = append(nav, cal.noop(locale.Months[first.Month()-1]+" "+strconv.Itoa(first.Year())))
+
+	if cal.allowed(next) {
+		button, err := cal.button("›", next, 0)
+		if err != nil {
+			return nil, err
+		}
+		nav = append(nav, button)
+	}
+
+	markup := keyboard.Inline(keyboard.InlineRow(nav...))
+
+	headings := make([]tgbotapi.InlineKeyboardButton, 7)
+	for i := range headings {
+		headings[i] = cal.noop(locale.Weekdays[(int(locale.FirstDay)+i)%7])
+	}
+	keyboard.InlineRow(headings...)(markup)
+
+	// Days before the first of the month are left blank.
+	lead := (int(first.Weekday()) - int(locale.FirstDay) + 7) % 7
+	week := make([]tgbotapi.InlineKeyboardButton, 0, 7)
+	for i := 0; i < lead; i++ {
+		week = append(week, cal.noop(" "))
+	}
+
+	for date := first; date.Month() == first.Month(); date = date.AddDate(0, 0, 1) {
+		if cal.allowed(date) {
+			button, err := cal.button(strconv.Itoa(date.Day()), first, date.Day())
+			if err != nil {
+				return nil, err
+			}
+			week = append(week, button)
+		} else {
+			week = append(week, cal.noop("·"))
+		}
+
+		if len(week) == 7 {
+			keyboard.InlineRow(week...)(markup)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, 7)
+		}
+	}
+
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, cal.noop(" "))
+		}
+		keyboard.InlineRow(week...)(markup)
+	}
+
+	return markup, nil
+}
+
+func (cal *Calendar) button(text string, month time.Time, day int) (tgbotapi.InlineKeyboardButton, error) {
+	return cal.bot.CallbackButton(text, cal.prefix, calendarState{
+		Month: month.Format("2006-01"),
+		Day:   day,
+	})
+}
+
+func (cal *Calendar) noop(text string) tgbotapi.InlineKeyboardButton {
+	return keyboard.Callback(text, cal.prefix+noopData)
+}
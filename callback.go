@@ -0,0 +1,257 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/keyboard"
+)
+
+// CallbackHandler handles presses of inline keyboard buttons.
+type CallbackHandler func(*Callback)
+
+// Callback is a press of an inline keyboard button, passed to the handler
+// registered for its data.
+type Callback struct {
+	tgbotapi.CallbackQuery
+
+	// Origin is the message with the button, or nil if it was sent through
+	// inline mode.
+	Origin *Message
+
+	// Payload is the button's data, after the prefix it was routed by.
+	Payload string
+
+	bot *Bot
+
+	lock     sync.Mutex
+	answered bool
+}
+
+// Answer stops the user's client showing the button press as in progress,
+// showing text as a brief notification if it isn't empty. Callbacks which
+// aren't answered by their handler are answered without any text.
+func (cb *Callback) Answer(text string) error {
+	return cb.answer(tgbotapi.NewCallback(cb.ID, text))
+}
+
+// Alert answers the button press with an alert the user must dismiss.
+func (cb *Callback) Alert(text string) error {
+	return cb.answer(tgbotapi.NewCallbackAlert(cb.ID, text))
+}
+
+func (cb *Callback) answer(config tgbotapi.CallbackConfig) error {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.answered {
+		return errors.New("telegram: callback has already been answered")
+	}
+	cb.answered = true
+
+	return cb.bot.api.AnswerCallbackQuery(config)
+}
+
+// Edit changes the text of the message with the button, with the
+// printf-formatted body, keeping its inline keyboard. Telegram doesn't say
+// what the keyboard of a message sent through inline mode is, so editing one
+// removes its keyboard; use EditMarkup afterwards to put one back.
+func (cb *Callback) Edit(f string, args ...interface{}) error {
+	if cb.Origin != nil {
+		return cb.Origin.Edit(f, args...)
+	}
+
+	_, err := cb.bot.api.EditMessageText(tgbotapi.EditMessageTextConfig{
+		EditTarget: tgbotapi.EditTarget{
+			InlineMessageID: cb.InlineMessageID,
+		},
+
+		Text: fmt.Sprintf(f, args...),
+	})
+
+	return err
+}
+
+// EditMarkup changes the inline keyboard of the message with the button, or
+// removes it if markup is nil.
+func (cb *Callback) EditMarkup(markup *tgbotapi.InlineKeyboardMarkup) error {
+	if cb.Origin != nil {
+		return cb.Origin.EditMarkup(markup)
+	}
+
+	_, err := cb.bot.api.EditMessageReplyMarkup(tgbotapi.EditMessageReplyMarkupConfig{
+		EditTarget: tgbotapi.EditTarget{
+			InlineMessageID: cb.InlineMessageID,
+		},
+
+		ReplyMarkup: markup,
+	})
+
+	return err
+}
+
+// show changes both the text and inline keyboard of the message with the
+// button, as widgets such as Pager do when navigated.
+func (cb *Callback) show(text string, markup *tgbotapi.InlineKeyboardMarkup) error {
+	if cb.Origin != nil {
+		return cb.Origin.WithMarkup(markup).Edit("%s", text)
+	}
+
+	_, err := cb.bot.api.EditMessageText(tgbotapi.EditMessageTextConfig{
+		EditTarget: tgbotapi.EditTarget{
+			InlineMessageID: cb.InlineMessageID,
+		},
+
+		Text:        text,
+		ReplyMarkup: markup,
+	})
+
+	return err
+}
+
+// fail tells the user that pressing the button went wrong, without showing
+// them err, which is logged if the bot is debugging.
+func (cb *Callback) fail(err error) {
+	if cb.bot.api.Debug {
+		log.Printf("telegram: callback %q failed: %v", cb.Data, err)
+	}
+
+	cb.Alert("Sorry, something went wrong.")
+}
+
+// CallbackRouter dispatches button presses to handlers by the prefix of
+// their data, such as "vote:" for buttons with data "vote:yes" and
+// "vote:no". The longest matching prefix wins.
+type CallbackRouter struct {
+	lock     sync.RWMutex
+	handlers map[string]CallbackHandler
+}
+
+// Handle registers handler for button presses whose data starts with prefix,
+// replacing any handler already registered for it.
+func (router *CallbackRouter) Handle(prefix string, handler CallbackHandler) {
+	router.lock.Lock()
+	defer router.lock.Unlock()
+
+	if router.handlers == nil {
+		router.handlers = make(map[string]CallbackHandler)
+	}
+
+	router.handlers[prefix] = handler
+}
+
+// match returns the handler for data, and the prefix it was registered for.
+func (router *CallbackRouter) match(data string) (CallbackHandler, string) {
+	router.lock.RLock()
+	defer router.lock.RUnlock()
+
+	var handler CallbackHandler
+	best := ""
+	for prefix, h := range router.handlers {
+		if strings.HasPrefix(data, prefix) && (handler == nil || len(prefix) > len(best)) {
+			handler = h
+			best = prefix
+		}
+	}
+
+	return handler, best
+}
+
+// CallbackButton is a button which sends payload back to the bot when
+// pressed, to be decoded and passed to the handler registered for prefix with
+// HandleCallbackData. payload is signed, so it can be trusted, and if it is
+// too large for the button, is kept by the bot's CallbackCodec instead.
+func (bot *Bot) CallbackButton(text, prefix string, payload interface{}) (tgbotapi.InlineKeyboardButton, error) {
+	data, err := bot.CallbackCodec.Encode(prefix, payload)
+	if err != nil {
+		return tgbotapi.InlineKeyboardButton{}, err
+	}
+
+	return keyboard.Callback(text, data), nil
+}
+
+// HandleCallbackData registers handler for presses of buttons made with
+// CallbackButton and prefix. prototype is a value of the type of their
+// payloads, and handler is passed a pointer to a new value of that type
+// holding the decoded payload.
+//
+// Presses of buttons whose payload has been tampered with, or has expired,
+// are answered with an alert, and not passed to handler. It panics if
+// prototype is nil, as the type of the payloads can't be told from it.
+func (bot *Bot) HandleCallbackData(prefix string, prototype interface{}, handler func(cb *Callback, payload interface{})) {
+	if prototype == nil {
+		panic("telegram: HandleCallbackData needs a prototype of the payload type, not nil")
+	}
+
+	typ := reflect.TypeOf(prototype)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	bot.HandleCallback(prefix, func(cb *Callback) {
+		payload := reflect.New(typ).Interface()
+
+		if err := bot.CallbackCodec.Decode(prefix, cb.Data, payload); err != nil {
+			if bot.api.Debug {
+				log.Printf("telegram: bad callback data %q: %v", cb.Data, err)
+			}
+
+			cb.Alert("This button has expired.")
+			return
+		}
+
+		handler(cb, payload)
+	})
+}
+
+// HandleCallback registers handler for presses of inline keyboard buttons
+// whose data starts with prefix.
+func (bot *Bot) HandleCallback(prefix string, handler CallbackHandler) {
+	bot.Callbacks.Handle(prefix, handler)
+}
+
+// DispatchCallback passes a button press to the handler registered for its
+// data, and returns false if there is none. Handlers are run in a goroutine
+// of their own. GetMessages and MessagesChan do this for every button press
+// they receive.
+func (bot *Bot) DispatchCallback(query tgbotapi.CallbackQuery) bool {
+	handler, prefix := bot.Callbacks.match(query.Data)
+	if handler == nil {
+		// Stop the button spinning, even though nothing handles it.
+		go bot.api.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return false
+	}
+
+	cb := &Callback{
+		CallbackQuery: query,
+
+		Payload: strings.TrimPrefix(query.Data, prefix),
+		bot:     bot,
+	}
+
+	if query.Message != nil {
+		origin := bot.sent(*query.Message)
+		cb.Origin = &origin
+	}
+
+	runHandler(func() {
+		handler(cb)
+
+		cb.lock.Lock()
+		answered := cb.answered
+		cb.lock.Unlock()
+
+		if !answered {
+			cb.Answer("")
+		}
+	}, func() {
+		cb.Alert("Fatal bot error. Sorry!")
+	})
+
+	return true
+}
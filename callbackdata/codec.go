@@ -0,0 +1,214 @@
+// Package callbackdata packs payloads into the callback data of inline
+// keyboard buttons, signed so that they can't be forged by a modified client.
+// Payloads too large for the 64 bytes Telegram allows are kept in a Store,
+// and the button carries a random key for them instead.
+package callbackdata
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MaxLength is the most callback data Telegram allows on a button, in bytes.
+const MaxLength = 64
+
+// The modes which follow the prefix in callback data.
+const (
+	modeInline = "i"
+	modeStored = "s"
+)
+
+// sigLength is the length of the encoded signature in callback data.
+var sigLength = base64.RawURLEncoding.EncodedLen(8)
+
+var (
+	// ErrTampered is returned when decoding callback data which wasn't
+	// made by the codec, or has been changed since.
+	ErrTampered = errors.New("callbackdata: signature does not match")
+
+	// ErrExpired is returned when decoding callback data whose payload has
+	// expired from the store.
+	ErrExpired = errors.New("callbackdata: payload has expired")
+
+	// ErrTooLarge is returned when encoding a payload which doesn't fit in
+	// callback data, with no store to put it in instead.
+	ErrTooLarge = errors.New("callbackdata: payload too large for callback data")
+)
+
+// Codec encodes and decodes payloads for callback data.
+//
+// Payloads are encoded as JSON, with structs packed into an array of their
+// exported fields' values, so data made by one version of a struct can't be
+// decoded into a version with its fields reordered.
+type Codec struct {
+	secret []byte
+
+	// Store, if set, keeps payloads which are too large for callback data.
+	Store Store
+
+	// TTL is how long stored payloads are kept.
+	TTL time.Duration
+}
+
+// New creates a Codec which signs payloads with secret. If secret is empty, a
+// random one is used, so data made before a restart can't be decoded after
+// it. store may be nil, when large payloads can't be encoded.
+func New(secret []byte, store Store) *Codec {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	}
+
+	return &Codec{
+		secret: secret,
+
+		Store: store,
+		TTL:   24 * time.Hour,
+	}
+}
+
+// Encode returns callback data for payload, starting with prefix, such as a
+// prefix registered with a callback router.
+func (codec *Codec) Encode(prefix string, payload interface{}) (string, error) {
+	body, err := pack(payload)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	data := prefix + modeInline + codec.sign(prefix, modeInline, encoded) + encoded
+	if len(data) <= MaxLength {
+		return data, nil
+	}
+
+	if codec.Store == nil {
+		return "", ErrTooLarge
+	}
+
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := codec.Store.Put(key, body, time.Now().Add(codec.TTL)); err != nil {
+		return "", err
+	}
+
+	data = prefix + modeStored + codec.sign(prefix, modeStored, key) + key
+	if len(data) > MaxLength {
+		return "", ErrTooLarge
+	}
+
+	return data, nil
+}
+
+// Decode checks callback data made by Encode with the same prefix, and
+// decodes its payload into the value payload points to.
+func (codec *Codec) Decode(prefix, data string, payload interface{}) error {
+	if !strings.HasPrefix(data, prefix) || len(data) < len(prefix)+1+sigLength {
+		return ErrTampered
+	}
+
+	rest := data[len(prefix):]
+	mode, sig, value := rest[:1], rest[1:1+sigLength], rest[1+sigLength:]
+
+	if !hmac.Equal([]byte(sig), []byte(codec.sign(prefix, mode, value))) {
+		return ErrTampered
+	}
+
+	var body []byte
+	switch mode {
+	case modeInline:
+		var err error
+		body, err = base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return ErrTampered
+		}
+	case modeStored:
+		if codec.Store == nil {
+			return ErrExpired
+		}
+
+		var err error
+		body, err = codec.Store.Get(value)
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrTampered
+	}
+
+	return unpack(body, payload)
+}
+
+// sign returns the encoded signature of the parts of callback data.
+func (codec *Codec) sign(prefix, mode, value string) string {
+	mac := hmac.New(sha256.New, codec.secret)
+	mac.Write([]byte(prefix))
+	mac.Write([]byte{0})
+	mac.Write([]byte(mode))
+	mac.Write([]byte(value))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// pack encodes payload as JSON, with structs as an array of their exported
+// fields' values.
+func pack(payload interface{}) ([]byte, error) {
+	v := reflect.Indirect(reflect.ValueOf(payload))
+	if v.Kind() != reflect.Struct {
+		return json.Marshal(payload)
+	}
+
+	var fields []interface{}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).PkgPath != "" {
+			continue
+		}
+
+		fields = append(fields, v.Field(i).Interface())
+	}
+
+	return json.Marshal(fields)
+}
+
+// unpack decodes data made by pack into the value payload points to.
+func unpack(data []byte, payload interface{}) error {
+	v := reflect.ValueOf(payload)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("callbackdata: payload to decode into must be a non-nil pointer")
+	}
+
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return json.Unmarshal(data, payload)
+	}
+
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.NumField() && len(fields) > 0; i++ {
+		if v.Type().Field(i).PkgPath != "" {
+			continue
+		}
+
+		if err := json.Unmarshal(fields[0], v.Field(i).Addr().Interface()); err != nil {
+			return err
+		}
+		fields = fields[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package callbackdata
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	Page   int
+	Query  string
+	hidden string
+	Done   bool
+}
+
+func TestRoundTrip(t *testing.T) {
+	codec := New([]byte("secret"), NewMemoryStore())
+
+	want := testPayload{Page: 3, Query: "cats", hidden: "dropped", Done: true}
+	data, err := codec.Encode("page:", want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(data, "page:") {
+		t.Errorf("data %q doesn't start with its prefix", data)
+	}
+
+	var got testPayload
+	if err := codec.Decode("page:", data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want.hidden = ""
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	data, err = codec.Encode("n:", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := codec.Decode("n:", data, &n); err != nil || n != 42 {
+		t.Errorf("got %d, %v, want 42", n, err)
+	}
+}
+
+func TestTampered(t *testing.T) {
+	codec := New([]byte("secret"), nil)
+
+	data, err := codec.Encode("p:", testPayload{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the last character of the payload.
+	tampered := data[:len(data)-1] + "A"
+	if tampered == data {
+		tampered = data[:len(data)-1] + "B"
+	}
+
+	for name, data := range map[string]string{
+		"payload changed": tampered,
+		"truncated":       data[:len("p:")+3],
+		"unknown mode":    "p:x" + data[len("p:")+1:],
+	} {
+		var got testPayload
+		if err := codec.Decode("p:", data, &got); err != ErrTampered {
+			t.Errorf("%s: got %v, want ErrTampered", name, err)
+		}
+	}
+
+	var got testPayload
+	if err := New([]byte("other"), nil).Decode("p:", data, &got); err != ErrTampered {
+		t.Errorf("other secret: got %v, want ErrTampered", err)
+	}
+}
+
+func TestWrongPrefix(t *testing.T) {
+	codec := New([]byte("secret"), nil)
+
+	data, err := codec.Encode("a:", testPayload{Page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got testPayload
+	if err := codec.Decode("b:", data, &got); err != ErrTampered {
+		t.Errorf("other prefix: got %v, want ErrTampered", err)
+	}
+
+	// The prefix is signed, so it can't be swapped for another.
+	if err := codec.Decode("b:", "b:"+data[len("a:"):], &got); err != ErrTampered {
+		t.Errorf("swapped prefix: got %v, want ErrTampered", err)
+	}
+}
+
+func TestStoreExpires(t *testing.T) {
+	codec := New([]byte("secret"), NewMemoryStore())
+	codec.TTL = 10 * time.Millisecond
+
+	data, err := codec.Encode("p:", strings.Repeat("x", 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	var got string
+	if err := codec.Decode("p:", data, &got); err != ErrExpired {
+		t.Errorf("got %v, want ErrExpired", err)
+	}
+}
+
+func TestStoredAtMaxLength(t *testing.T) {
+	codec := New([]byte("secret"), NewMemoryStore())
+
+	// With the prefix, mode and signature taking 14 bytes, a 35 character
+	// string encodes to exactly MaxLength, and one more doesn't fit.
+	for _, test := range []struct {
+		length int
+		mode   string
+	}{
+		{35, modeInline},
+		{36, modeStored},
+	} {
+		payload := strings.Repeat("x", test.length)
+
+		data, err := codec.Encode("p:", payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > MaxLength {
+			t.Errorf("%d characters: data is %d bytes", test.length, len(data))
+		}
+		if mode := data[len("p:") : len("p:")+1]; mode != test.mode {
+			t.Errorf("%d characters: encoded as %q, want %q", test.length, mode, test.mode)
+		}
+
+		var got string
+		if err := codec.Decode("p:", data, &got); err != nil || got != payload {
+			t.Errorf("%d characters: got %q, %v", test.length, got, err)
+		}
+	}
+
+	if _, err := New([]byte("secret"), nil).Encode("p:", strings.Repeat("x", 36)); err != ErrTooLarge {
+		t.Errorf("without a store: got %v, want ErrTooLarge", err)
+	}
+}
@@ -0,0 +1,77 @@
+package callbackdata
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps payloads too large for callback data until they expire.
+// Implement it to keep them somewhere shared between processes, or which
+// survives restarts.
+type Store interface {
+	// Put keeps data under key until expires.
+	Put(key string, data []byte, expires time.Time) error
+
+	// Get returns the data kept under key, or ErrExpired if there is none.
+	Get(key string) ([]byte, error)
+}
+
+// MemoryStore is a Store which only lasts as long as the process.
+type MemoryStore struct {
+	lock      sync.Mutex
+	payloads  map[string]storedPayload
+	nextPurge time.Time
+}
+
+type storedPayload struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		payloads: make(map[string]storedPayload),
+	}
+}
+
+// Put keeps data under key until expires. Payloads which have expired are
+// dropped every so often as more are put.
+func (store *MemoryStore) Put(key string, data []byte, expires time.Time) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	// Expired payloads are cleared out every so often, rather than each
+	// having a timer.
+	now := time.Now()
+	if now.After(store.nextPurge) {
+		for key, payload := range store.payloads {
+			if now.After(payload.expires) {
+				delete(store.payloads, key)
+			}
+		}
+
+		store.nextPurge = now.Add(time.Minute)
+	}
+
+	store.payloads[key] = storedPayload{
+		data:    data,
+		expires: expires,
+	}
+
+	return nil
+}
+
+// Get returns the data kept under key, or ErrExpired if there is none or it
+// has expired.
+func (store *MemoryStore) Get(key string) ([]byte, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	payload, ok := store.payloads[key]
+	if !ok || time.Now().After(payload.expires) {
+		return nil, ErrExpired
+	}
+
+	return payload.data, nil
+}
@@ -39,8 +39,12 @@ type Command struct {
 // Match takes the given Message and returns true if the message is
 // a) Addressing the bot & command
 // b) Appropiate to this context
+// Messages without text, such as photos, are matched by their caption.
 func (cmd *Command) Match(msg Message) bool {
 	inp := strings.TrimSpace(msg.Text)
+	if inp == "" {
+		inp = strings.TrimSpace(msg.Caption)
+	}
 	self := msg.bot.api.Self
 
 	if inp == "/"+cmd.Name {
@@ -88,22 +92,11 @@ func (cmds *Commands) Add(cmd Command) {
 func (cmds *Commands) Handle(msg Message) bool {
 	for _, cmd := range *cmds {
 		if cmd.Match(msg) {
-			go func() {
-				defer func() {
-					err := recover()
-
-					if err != nil {
-						const size = 64 << 10
-						buf := make([]byte, size)
-						buf = buf[:runtime.Stack(buf, false)]
-
-						log.Printf("Recovered from crash: %+v\n%s", err, buf)
-						msg.ReplyWith("Fatal bot error. Sorry!").Send()
-					}
-				}()
-
+			runHandler(func() {
 				cmd.Handle(msg)
-			}()
+			}, func() {
+				msg.ReplyWith("Fatal bot error. Sorry!").Send()
+			})
 
 			return true
 		}
@@ -112,6 +105,27 @@ func (cmds *Commands) Handle(msg Message) bool {
 	return false
 }
 
+// runHandler runs handler in a goroutine of its own. If it panics, the panic
+// is logged, and crashed is called to tell the user something went wrong.
+func runHandler(handler, crashed func()) {
+	go func() {
+		defer func() {
+			err := recover()
+
+			if err != nil {
+				const size = 64 << 10
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+
+				log.Printf("Recovered from crash: %+v\n%s", err, buf)
+				crashed()
+			}
+		}()
+
+		handler()
+	}()
+}
+
 // Help generates a Help text blob for the given commands.
 // pass true to not include commands disabled for groups.
 func (cmds *Commands) Help(groupChat bool) string {
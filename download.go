@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// ErrNoFile is returned when downloading from a message without the
+// requested kind of file attached.
+var ErrNoFile = errors.New("telegram: message has no file to download")
+
+// LargestPhoto returns the largest size of the photo attached to this
+// message, and false if there is no photo.
+func (msg Message) LargestPhoto() (tgbotapi.PhotoSize, bool) {
+	if len(msg.Photo) == 0 {
+		return tgbotapi.PhotoSize{}, false
+	}
+
+	return largestPhoto(msg.Photo), true
+}
+
+// DownloadPhoto writes the largest size of the photo attached to this message
+// into w.
+func (msg Message) DownloadPhoto(ctx context.Context, w io.Writer) error {
+	photo, ok := msg.LargestPhoto()
+	if !ok {
+		return ErrNoFile
+	}
+
+	return msg.bot.api.Download(ctx, photo.FileID, w)
+}
+
+// DownloadDocument writes the document attached to this message into w.
+func (msg Message) DownloadDocument(ctx context.Context, w io.Writer) error {
+	if msg.Document.FileID == "" {
+		return ErrNoFile
+	}
+
+	return msg.bot.api.Download(ctx, msg.Document.FileID, w)
+}
+
+// Download writes whatever file is attached to this message into w, using
+// the largest size of photos.
+func (msg Message) Download(ctx context.Context, w io.Writer) error {
+	fileID := mediaFileID(msg.Message)
+	if fileID == "" {
+		return ErrNoFile
+	}
+
+	return msg.bot.api.Download(ctx, fileID, w)
+}
+
+// Open returns the contents of whatever file is attached to this message,
+// using the largest size of photos. Files come from the bot's MediaCache if
+// it has one, and are streamed straight from Telegram otherwise.
+func (msg Message) Open(ctx context.Context) (io.ReadCloser, error) {
+	fileID, uniqueID := mediaFile(msg.Message)
+	if fileID == "" {
+		return nil, ErrNoFile
+	}
+
+	if msg.bot.MediaCache != nil {
+		return msg.bot.MediaCache.Open(ctx, fileID, uniqueID)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(msg.bot.api.Download(ctx, fileID, w))
+	}()
+
+	return r, nil
+}
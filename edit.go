@@ -0,0 +1,119 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// ErrNotSent is returned when editing a message which the bot hasn't sent.
+var ErrNotSent = errors.New("telegram: message has not been sent by the bot")
+
+// editTarget returns the message to edit, or ErrNotSent.
+func (msg Message) editTarget() (tgbotapi.EditTarget, error) {
+	if msg.dir != outgoing || msg.MessageID == 0 {
+		return tgbotapi.EditTarget{}, ErrNotSent
+	}
+
+	return tgbotapi.EditTarget{
+		ChatID:    msg.chatRef(),
+		MessageID: msg.MessageID,
+	}, nil
+}
+
+// inlineMarkup returns the inline keyboard attached to the message, so edits
+// keep it.
+func (msg Message) inlineMarkup() *tgbotapi.InlineKeyboardMarkup {
+	switch markup := msg.opts.ReplyMarkup.(type) {
+	case tgbotapi.InlineKeyboardMarkup:
+		return &markup
+	case *tgbotapi.InlineKeyboardMarkup:
+		return markup
+	}
+
+	return nil
+}
+
+// edited updates the message after an edit. Edits which changed nothing
+// return no message, and leave it as it was.
+func (msg *Message) edited(message tgbotapi.Message) {
+	if message.MessageID != 0 {
+		msg.Message = message
+	}
+}
+
+// Edit changes the text of a message the bot has sent, with the
+// printf-formatted body, or its caption if it is a photo or other media.
+// Its inline keyboard, if any, is kept. Editing a message to what it already
+// says succeeds without doing anything.
+func (msg *Message) Edit(f string, args ...interface{}) error {
+	target, err := msg.editTarget()
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(f, args...)
+
+	var edited tgbotapi.Message
+	if mediaFileID(msg.Message) != "" {
+		edited, err = msg.bot.api.EditMessageCaption(tgbotapi.EditMessageCaptionConfig{
+			EditTarget: target,
+
+			Caption:     text,
+			ReplyMarkup: msg.inlineMarkup(),
+		})
+	} else {
+		edited, err = msg.bot.api.EditMessageText(tgbotapi.EditMessageTextConfig{
+			EditTarget: target,
+
+			Text:               text,
+			LinkPreviewOptions: msg.opts.LinkPreviewOptions,
+			ReplyMarkup:        msg.inlineMarkup(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	msg.edited(edited)
+
+	return nil
+}
+
+// EditMarkup changes the inline keyboard of a message the bot has sent, or
+// removes it if markup is nil.
+func (msg *Message) EditMarkup(markup *tgbotapi.InlineKeyboardMarkup) error {
+	target, err := msg.editTarget()
+	if err != nil {
+		return err
+	}
+
+	edited, err := msg.bot.api.EditMessageReplyMarkup(tgbotapi.EditMessageReplyMarkupConfig{
+		EditTarget: target,
+
+		ReplyMarkup: markup,
+	})
+	if err != nil {
+		return err
+	}
+
+	if markup != nil {
+		msg.opts.ReplyMarkup = *markup
+	} else {
+		msg.opts.ReplyMarkup = nil
+	}
+	msg.edited(edited)
+
+	return nil
+}
+
+// Delete deletes the message. Bots can always delete the messages they sent
+// in the last 48 hours, and other messages in groups they administer.
+func (msg *Message) Delete() error {
+	if msg.MessageID == 0 {
+		return ErrNotSent
+	}
+
+	return msg.bot.api.DeleteMessage(tgbotapi.NewDeleteMessage(msg.chatRef(), msg.MessageID))
+}
@@ -0,0 +1,123 @@
+package keyboard
+
+import (
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// Buttons makes plain buttons, which send their text when pressed.
+func Buttons(texts ...string) []tgbotapi.KeyboardButton {
+	buttons := make([]tgbotapi.KeyboardButton, len(texts))
+	for i, text := range texts {
+		buttons[i] = tgbotapi.KeyboardButton{Text: text}
+	}
+
+	return buttons
+}
+
+// Contact is a button which sends the user's phone number.
+func Contact(text string) tgbotapi.KeyboardButton {
+	return tgbotapi.KeyboardButton{
+		Text:           text,
+		RequestContact: true,
+	}
+}
+
+// Location is a button which sends the user's current location.
+func Location(text string) tgbotapi.KeyboardButton {
+	return tgbotapi.KeyboardButton{
+		Text:            text,
+		RequestLocation: true,
+	}
+}
+
+// Poll is a button which has the user create a poll, of pollType "quiz" or
+// "regular", or either if it is empty.
+func Poll(text, pollType string) tgbotapi.KeyboardButton {
+	return tgbotapi.KeyboardButton{
+		Text:        text,
+		RequestPoll: &tgbotapi.KeyboardButtonPollType{Type: pollType},
+	}
+}
+
+// Users is a button which has the user pick users to share with the bot.
+func Users(text string, request tgbotapi.KeyboardButtonRequestUsers) tgbotapi.KeyboardButton {
+	return tgbotapi.KeyboardButton{
+		Text:         text,
+		RequestUsers: &request,
+	}
+}
+
+// Chat is a button which has the user pick a chat to share with the bot.
+func Chat(text string, request tgbotapi.KeyboardButtonRequestChat) tgbotapi.KeyboardButton {
+	return tgbotapi.KeyboardButton{
+		Text:        text,
+		RequestChat: &request,
+	}
+}
+
+// WebApp is a button which opens the Web App at url. Only private chats
+// can have these.
+func WebApp(text, url string) tgbotapi.KeyboardButton {
+	return tgbotapi.KeyboardButton{
+		Text:   text,
+		WebApp: &tgbotapi.WebAppInfo{URL: url},
+	}
+}
+
+// Grid lays buttons out in rows of columns buttons, with what is left over
+// in a shorter last row.
+func Grid(columns int, buttons ...tgbotapi.KeyboardButton) Modifier {
+	return func(kbd *tgbotapi.ReplyKeyboardMarkup) {
+		n := columns
+		if n < 1 {
+			n = 1
+		}
+
+		for i := 0; i < len(buttons); i += n {
+			end := i + n
+			if end > len(buttons) {
+				end = len(buttons)
+			}
+
+			ButtonRow(buttons[i:end]...)(kbd)
+		}
+	}
+}
+
+// Wrap lays buttons out in rows, starting a new row whenever the text of the
+// buttons on it would be wider than width characters. Buttons wider than that
+// on their own get a row to themselves.
+func Wrap(width int, buttons ...tgbotapi.KeyboardButton) Modifier {
+	return func(kbd *tgbotapi.ReplyKeyboardMarkup) {
+		start, used := 0, 0
+		for i, button := range buttons {
+			w := textWidth(button.Text)
+			if i > start && used+w > width {
+				ButtonRow(buttons[start:i]...)(kbd)
+				start, used = i, 0
+			}
+
+			used += w
+		}
+
+		if start < len(buttons) {
+			ButtonRow(buttons[start:]...)(kbd)
+		}
+	}
+}
+
+// textWidth returns roughly how many characters wide text is shown, counting
+// emoji and East Asian characters as two.
+func textWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		width++
+		if r >= 0x1100 && (r <= 0x115F || r >= 0x2E80 && r <= 0xA4CF ||
+			r >= 0xAC00 && r <= 0xD7A3 || r >= 0xF900 && r <= 0xFAFF ||
+			r >= 0xFF00 && r <= 0xFF60 || r >= 0x1F300) {
+			width++
+		}
+	}
+
+	return width
+}
@@ -0,0 +1,91 @@
+package keyboard
+
+import (
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// InlineModifier changes an inline keyboard, such as by adding a row of
+// buttons to it.
+type InlineModifier func(*tgbotapi.InlineKeyboardMarkup)
+
+// Inline builds an inline keyboard, attached to the message itself, out of
+// the given modifiers.
+func Inline(mods ...InlineModifier) *tgbotapi.InlineKeyboardMarkup {
+	markup := &tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}
+
+	for _, mod := range mods {
+		mod(markup)
+	}
+
+	return markup
+}
+
+// InlineRow adds a row of buttons to an inline keyboard.
+func InlineRow(buttons ...tgbotapi.InlineKeyboardButton) InlineModifier {
+	return func(kbd *tgbotapi.InlineKeyboardMarkup) {
+		kbd.InlineKeyboard = append(kbd.InlineKeyboard, buttons)
+	}
+}
+
+// InlineColumn adds each button as a row of its own.
+func InlineColumn(buttons ...tgbotapi.InlineKeyboardButton) InlineModifier {
+	return func(kbd *tgbotapi.InlineKeyboardMarkup) {
+		for _, button := range buttons {
+			InlineRow(button)(kbd)
+		}
+	}
+}
+
+// Callback is a button which sends data back to the bot when pressed, to
+// be handled by Bot.HandleCallback. Telegram allows up to 64 bytes of data.
+func Callback(text, data string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.InlineKeyboardButton{
+		Text:         text,
+		CallbackData: data,
+	}
+}
+
+// URL is a button which opens url.
+func URL(text, url string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.InlineKeyboardButton{
+		Text: text,
+		URL:  url,
+	}
+}
+
+// SwitchInline is a button which has the user pick a chat, and starts an
+// inline query of the bot there with query.
+func SwitchInline(text, query string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.InlineKeyboardButton{
+		Text:              text,
+		SwitchInlineQuery: &query,
+	}
+}
+
+// SwitchInlineHere is a button which starts an inline query of the bot with
+// query in the same chat.
+func SwitchInlineHere(text, query string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.InlineKeyboardButton{
+		Text:                         text,
+		SwitchInlineQueryCurrentChat: &query,
+	}
+}
+
+// CopyText is a button which copies copy to the clipboard.
+func CopyText(text, copy string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.InlineKeyboardButton{
+		Text:     text,
+		CopyText: &tgbotapi.CopyTextButton{Text: copy},
+	}
+}
+
+// Login is a button which logs the user in to the website at url with their
+// Telegram account.
+func Login(text, url string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.InlineKeyboardButton{
+		Text:     text,
+		LoginURL: &tgbotapi.LoginURL{URL: url},
+	}
+}
@@ -1,11 +1,40 @@
 package keyboard
 
-import (	
+import (
 	"github.com/AmandaCameron/go-telegram/api"
 )
 
 type Modifier func(*tgbotapi.ReplyKeyboardMarkup)
 
+// New builds a custom keyboard out of the given modifiers.
+func New(mods ...Modifier) tgbotapi.ReplyMarkup {
+	markup := tgbotapi.ReplyKeyboardMarkup{}
+
+	for _, mod := range mods {
+		mod(&markup)
+	}
+
+	return markup
+}
+
+// Remove removes any custom keyboard currently shown.
+// if `selective` is set, it will only go to one user.
+func Remove(selective bool) tgbotapi.ReplyMarkup {
+	return tgbotapi.ReplyKeyboardRemove{
+		RemoveKeyboard: true,
+		Selective:      selective,
+	}
+}
+
+// ForceReply has the user's client reply to the message straight away.
+// if `selective` is set, it will only go to one user.
+func ForceReply(selective bool) tgbotapi.ReplyMarkup {
+	return tgbotapi.ForceReply{
+		ForceReply: true,
+		Selective:  selective,
+	}
+}
+
 func List(items ...string) Modifier {
 	return func(kbd *tgbotapi.ReplyKeyboardMarkup) {
 		for _, row := range items {
@@ -15,8 +44,14 @@ func List(items ...string) Modifier {
 }
 
 func Row(row ...string) Modifier {
+	return ButtonRow(Buttons(row...)...)
+}
+
+// ButtonRow adds a row of buttons, such as ones requesting the user's
+// contact or location, to a keyboard.
+func ButtonRow(buttons ...tgbotapi.KeyboardButton) Modifier {
 	return func(kbd *tgbotapi.ReplyKeyboardMarkup) {
-		kbd.Keyboard = append(kbd.Keyboard, row)
+		kbd.Keyboard = append(kbd.Keyboard, buttons)
 	}
 }
 
@@ -27,3 +62,22 @@ func Once(kbd *tgbotapi.ReplyKeyboardMarkup) {
 func Selective(kbd *tgbotapi.ReplyKeyboardMarkup) {
 	kbd.Selective = true
 }
+
+// Persistent keeps the keyboard shown when the user's own keyboard is open,
+// instead of folding it away behind a button.
+func Persistent(kbd *tgbotapi.ReplyKeyboardMarkup) {
+	kbd.IsPersistent = true
+}
+
+// Resize has the user's client fit the keyboard to its buttons, rather than
+// making it as tall as the user's own keyboard.
+func Resize(kbd *tgbotapi.ReplyKeyboardMarkup) {
+	kbd.ResizeKeyboard = true
+}
+
+// Placeholder shows text in the input field while the keyboard is shown.
+func Placeholder(text string) Modifier {
+	return func(kbd *tgbotapi.ReplyKeyboardMarkup) {
+		kbd.InputFieldPlaceholder = text
+	}
+}
@@ -0,0 +1,255 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// maxMessageLength is the longest text a message can have, in UTF-16 code
+// units.
+const maxMessageLength = 4096
+
+// ErrWriterClosed is returned when writing to a LiveWriter after Close.
+var ErrWriterClosed = errors.New("telegram: write to closed LiveWriter")
+
+// LiveWriter is an io.WriteCloser which streams text into a chat, such as the
+// output of a build. The first text written is sent as a message, which is
+// then edited as more arrives. Writes are gathered up and sent every few
+// seconds, and text which doesn't fit in one message carries on in a new one.
+//
+// Close sends anything still waiting. If sending fails, the error is returned
+// from the next Write or Close.
+type LiveWriter struct {
+	template *Message
+
+	lock    sync.Mutex
+	text    string
+	partial []byte
+	closed  bool
+	err     error
+	last    time.Time
+	timer   *time.Timer
+
+	// editing is held while text is being sent, so it is sent in order.
+	editing sync.Mutex
+	current *Message
+	shown   string
+}
+
+// LiveWriter returns a LiveWriter which streams text into the specified chat.
+func (bot *Bot) LiveWriter(chat tgbotapi.ChatRef) *LiveWriter {
+	return &LiveWriter{
+		template: bot.Message(chat, ""),
+	}
+}
+
+// LiveReply returns a LiveWriter which streams text into a reply to this
+// message.
+func (msg Message) LiveReply() *LiveWriter {
+	template := msg.ReplyWith("")
+	if template == nil {
+		template = msg.bot.Message(msg.chatRef(), "")
+	}
+
+	return &LiveWriter{
+		template: template,
+	}
+}
+
+// Write adds p to the text being streamed.
+func (lw *LiveWriter) Write(p []byte) (int, error) {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	if lw.closed {
+		return 0, ErrWriterClosed
+	}
+	if lw.err != nil {
+		return 0, lw.err
+	}
+
+	// Multi-byte characters split between writes are held back until the
+	// rest of them arrives.
+	data := append(lw.partial, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+
+	lw.text += string(data[:cut])
+	lw.partial = append([]byte(nil), data[cut:]...)
+	lw.schedule()
+
+	return len(p), nil
+}
+
+// Close sends any text still waiting to be sent.
+func (lw *LiveWriter) Close() error {
+	lw.lock.Lock()
+	if lw.closed {
+		lw.lock.Unlock()
+		return ErrWriterClosed
+	}
+
+	lw.closed = true
+	if lw.timer != nil {
+		lw.timer.Stop()
+		lw.timer = nil
+	}
+
+	lw.text += string(lw.partial)
+	lw.partial = nil
+	wait := time.Until(lw.last.Add(progressInterval))
+	lw.lock.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	for delay := lw.flush(); delay > 0; delay = lw.flush() {
+		time.Sleep(delay)
+	}
+
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	return lw.err
+}
+
+// schedule arranges for the text to be sent once enough time has passed since
+// it last was. The lock must be held.
+func (lw *LiveWriter) schedule() {
+	if lw.timer != nil {
+		return
+	}
+
+	wait := time.Until(lw.last.Add(progressInterval))
+	if wait < 0 {
+		wait = 0
+	}
+
+	lw.timer = time.AfterFunc(wait, func() {
+		lw.lock.Lock()
+		lw.timer = nil
+		lw.lock.Unlock()
+
+		lw.flush()
+	})
+}
+
+// flush sends the text written so far, filling up messages and starting new
+// ones as needed. If Telegram asks for it to slow down, it returns how long
+// to wait before trying again.
+func (lw *LiveWriter) flush() time.Duration {
+	lw.editing.Lock()
+	defer lw.editing.Unlock()
+
+	lw.lock.Lock()
+	if lw.err != nil {
+		lw.lock.Unlock()
+		return 0
+	}
+	text := lw.text
+	lw.lock.Unlock()
+
+	// consumed is how much of text went into messages which are now full.
+	consumed := 0
+
+	var err error
+	for {
+		head, rest := splitMessage(text)
+		if head != lw.shown && strings.TrimSpace(head) != "" {
+			err = lw.show(head)
+		}
+		if err != nil || rest == "" {
+			break
+		}
+
+		// A full message is left as it is, and the rest of the text
+		// goes into a new one.
+		consumed += len(text) - len(rest)
+		text = rest
+		lw.current = nil
+		lw.shown = ""
+	}
+
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+
+	// Writes only add to the text, so what was consumed is still at the
+	// start of it.
+	lw.text = lw.text[consumed:]
+	lw.last = time.Now()
+
+	delay := retryAfter(err)
+	if delay > 0 {
+		// Being asked to slow down isn't fatal; try again later.
+		lw.last = lw.last.Add(delay)
+		if !lw.closed {
+			lw.schedule()
+		}
+	} else if err != nil {
+		lw.err = err
+	}
+
+	return delay
+}
+
+// show sends text as the current message, or edits the current message to
+// it.
+func (lw *LiveWriter) show(text string) error {
+	if lw.current == nil {
+		msg := *lw.template
+		msg.Text = text
+
+		if err := msg.Send(); err != nil {
+			return err
+		}
+
+		// Only the first message replies to anything.
+		lw.template.opts.ReplyToMessageID = 0
+		lw.template.opts.ReplyParameters = nil
+
+		lw.current = &msg
+	} else if err := lw.current.Edit("%s", text); err != nil {
+		return err
+	}
+
+	lw.shown = text
+
+	return nil
+}
+
+// splitMessage splits off as much of text as fits in a message, at the end
+// of a line if it can.
+func splitMessage(text string) (string, string) {
+	length := 0
+	for i, r := range text {
+		length++
+		if r >= 0x10000 {
+			// Needs a surrogate pair in UTF-16.
+			length++
+		}
+		if length <= maxMessageLength {
+			continue
+		}
+
+		if newline := strings.LastIndexByte(text[:i], '\n'); newline > 0 {
+			return text[:newline], text[newline+1:]
+		}
+
+		return text[:i], text[i:]
+	}
+
+	return text, ""
+}
@@ -0,0 +1,521 @@
+package telegram
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// mediaReply is a message sent through one of the Bot API's Send methods.
+type mediaReply struct {
+	send func() (tgbotapi.Message, error)
+}
+
+func (mr mediaReply) Send() error {
+	_, err := mr.send()
+
+	return err
+}
+
+// Upload sends the message, and returns the FileID of the media in it.
+func (mr mediaReply) Upload() (string, error) {
+	sent, err := mr.send()
+	if err != nil {
+		return "", err
+	}
+
+	fileID := mediaFileID(sent)
+	if fileID == "" {
+		return "", fmt.Errorf("Unspecified error happen in the telegram bot API.")
+	}
+
+	return fileID, nil
+}
+
+// reply wraps send, which sends something in response to msg, so that what
+// it sends self-destructs if msg was set to.
+func (msg *Message) reply(send func() (tgbotapi.Message, error)) mediaReply {
+	return mediaReply{
+		send: func() (tgbotapi.Message, error) {
+			sent, err := send()
+			if err == nil {
+				msg.scheduleDeletion(sent)
+			}
+
+			return sent, err
+		},
+	}
+}
+
+// mediaFileID returns the FileID of the media attached to msg, using the
+// largest size for photos.
+func mediaFileID(msg tgbotapi.Message) string {
+	fileID, _ := mediaFile(msg)
+	return fileID
+}
+
+// mediaFile returns the FileID and FileUniqueID of the media attached to msg,
+// using the largest size for photos.
+func mediaFile(msg tgbotapi.Message) (string, string) {
+	if len(msg.Photo) > 0 {
+		photo := largestPhoto(msg.Photo)
+		return photo.FileID, photo.FileUniqueID
+	}
+
+	// Animations come with a Document too, so are checked first.
+	switch {
+	case msg.Animation.FileID != "":
+		return msg.Animation.FileID, msg.Animation.FileUniqueID
+	case msg.Document.FileID != "":
+		return msg.Document.FileID, msg.Document.FileUniqueID
+	case msg.Audio.FileID != "":
+		return msg.Audio.FileID, msg.Audio.FileUniqueID
+	case msg.Video.FileID != "":
+		return msg.Video.FileID, msg.Video.FileUniqueID
+	case msg.Sticker.FileID != "":
+		return msg.Sticker.FileID, msg.Sticker.FileUniqueID
+	case msg.Voice.FileID != "":
+		return msg.Voice.FileID, msg.Voice.FileUniqueID
+	case msg.VideoNote.FileID != "":
+		return msg.VideoNote.FileID, msg.VideoNote.FileUniqueID
+	}
+
+	return "", ""
+}
+
+// largestPhoto returns the size of a photo with the most pixels.
+func largestPhoto(sizes []tgbotapi.PhotoSize) tgbotapi.PhotoSize {
+	var top tgbotapi.PhotoSize
+
+	for _, photo := range sizes {
+		if photo.Width*photo.Height > top.Width*top.Height {
+			top = photo
+		}
+	}
+
+	return top
+}
+
+// MediaOption sets optional metadata on an outgoing media message. Options
+// which don't apply to the kind of media being sent are ignored.
+type MediaOption func(*mediaInfo)
+
+type mediaInfo struct {
+	parseMode                   string
+	duration                    int32
+	width, height               int32
+	performer, title            string
+	thumbnail                   tgbotapi.InputFile
+	supportsStreaming           bool
+	hasSpoiler                  bool
+	disableContentTypeDetection bool
+	progress                    tgbotapi.ProgressFunc
+	uploadAction                bool
+	preparePhoto                bool
+}
+
+func mediaOptions(opts []MediaOption) mediaInfo {
+	var info mediaInfo
+
+	for _, opt := range opts {
+		opt(&info)
+	}
+
+	return info
+}
+
+// ParseMode sets how the caption is formatted, either "MarkdownV2" or "HTML".
+func ParseMode(mode string) MediaOption {
+	return func(info *mediaInfo) {
+		info.parseMode = mode
+	}
+}
+
+// Duration sets the length of audio, voice messages, video, animations and
+// video notes.
+func Duration(d time.Duration) MediaOption {
+	return func(info *mediaInfo) {
+		info.duration = int32(d / time.Second)
+	}
+}
+
+// Dimensions sets the width and height of a video or animation. Video notes
+// are square, and use the width as their diameter.
+func Dimensions(width, height int32) MediaOption {
+	return func(info *mediaInfo) {
+		info.width = width
+		info.height = height
+	}
+}
+
+// Performer sets the performer and track title of audio.
+func Performer(performer, title string) MediaOption {
+	return func(info *mediaInfo) {
+		info.performer = performer
+		info.title = title
+	}
+}
+
+// Thumbnail uploads file, a JPEG, as the thumbnail of audio, a video, an
+// animation, a video note or a document.
+func Thumbnail(file tgbotapi.InputFile) MediaOption {
+	return func(info *mediaInfo) {
+		info.thumbnail = file
+	}
+}
+
+// Streaming marks a video as suitable for streaming.
+func Streaming(info *mediaInfo) {
+	info.supportsStreaming = true
+}
+
+// Spoiler hides a photo, video or animation behind a spoiler animation.
+func Spoiler(info *mediaInfo) {
+	info.hasSpoiler = true
+}
+
+// NoContentTypeDetection stops Telegram treating an uploaded document as
+// some other kind of media.
+func NoContentTypeDetection(info *mediaInfo) {
+	info.disableContentTypeDetection = true
+}
+
+// OnProgress has fn called as the media is uploaded.
+func OnProgress(fn tgbotapi.ProgressFunc) MediaOption {
+	return func(info *mediaInfo) {
+		info.progress = fn
+	}
+}
+
+// ShowUploadAction shows the chat that the bot is uploading the media, such
+// as "sending a video", for as long as the upload takes.
+func ShowUploadAction(info *mediaInfo) {
+	info.uploadAction = true
+}
+
+// PreparePhoto scales down uploaded photos which are too large for Telegram,
+// re-encoding them if needed, and strips their EXIF and other metadata. The
+// photos are read into memory to do this; otherwise they are streamed.
+func PreparePhoto(info *mediaInfo) {
+	info.preparePhoto = true
+}
+
+// progressFor returns the ProgressFunc to upload media with, showing action
+// in chat if ShowUploadAction was given.
+func (info mediaInfo) progressFor(bot *Bot, chat tgbotapi.ChatRef, action tgbotapi.ChatAction) tgbotapi.ProgressFunc {
+	if !info.uploadAction {
+		return info.progress
+	}
+
+	return bot.api.ChatActionProgress(chat, action, info.progress)
+}
+
+func (msg *Message) sendPhoto(config tgbotapi.PhotoConfig, caption string, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Caption = caption
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadPhoto)
+	config.ParseMode = info.parseMode
+	config.HasSpoiler = info.hasSpoiler
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendPhoto(config)
+	})
+}
+
+func (msg *Message) sendDocument(config tgbotapi.DocumentConfig, caption string, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Caption = caption
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadDocument)
+	config.ParseMode = info.parseMode
+	config.Thumbnail = info.thumbnail
+	config.DisableContentTypeDetection = info.disableContentTypeDetection
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendDocument(config)
+	})
+}
+
+func (msg *Message) sendAudio(config tgbotapi.AudioConfig, caption string, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Caption = caption
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadAudio)
+	config.ParseMode = info.parseMode
+	config.Duration = info.duration
+	config.Performer = info.performer
+	config.Title = info.title
+	config.Thumbnail = info.thumbnail
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendAudio(config)
+	})
+}
+
+func (msg *Message) sendVideo(config tgbotapi.VideoConfig, caption string, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Caption = caption
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadVideo)
+	config.ParseMode = info.parseMode
+	config.Duration = info.duration
+	config.Width = info.width
+	config.Height = info.height
+	config.Thumbnail = info.thumbnail
+	config.SupportsStreaming = info.supportsStreaming
+	config.HasSpoiler = info.hasSpoiler
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendVideo(config)
+	})
+}
+
+func (msg *Message) sendVoice(config tgbotapi.VoiceConfig, caption string, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Caption = caption
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadVoice)
+	config.ParseMode = info.parseMode
+	config.Duration = info.duration
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendVoice(config)
+	})
+}
+
+func (msg *Message) sendAnimation(config tgbotapi.AnimationConfig, caption string, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Caption = caption
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadVideo)
+	config.ParseMode = info.parseMode
+	config.Duration = info.duration
+	config.Width = info.width
+	config.Height = info.height
+	config.Thumbnail = info.thumbnail
+	config.HasSpoiler = info.hasSpoiler
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendAnimation(config)
+	})
+}
+
+func (msg *Message) sendVideoNote(config tgbotapi.VideoNoteConfig, opts []MediaOption) mediaReply {
+	info := mediaOptions(opts)
+
+	config.SendOptions = msg.replyOptions()
+	config.Progress = info.progressFor(msg.bot, config.ChatID, tgbotapi.ChatUploadVideoNote)
+	config.Duration = info.duration
+	config.Length = info.width
+	config.Thumbnail = info.thumbnail
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendVideoNote(config)
+	})
+}
+
+// DocumentReply sends an already-uploaded document as a reply to this message.
+func (msg *Message) DocumentReply(fileID, caption string, opts ...MediaOption) Sendable {
+	return msg.sendDocument(tgbotapi.NewDocumentShare(msg.chatRef(), fileID), caption, opts)
+}
+
+// UploadDocument sends file as a document, uploading it if needed, as a reply
+// to this message.
+func (msg *Message) UploadDocument(file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return msg.sendDocument(tgbotapi.NewDocument(msg.chatRef(), file), caption, opts)
+}
+
+// AudioReply sends an already-uploaded audio file as a reply to this message.
+func (msg *Message) AudioReply(fileID, caption string, opts ...MediaOption) Sendable {
+	return msg.sendAudio(tgbotapi.NewAudioShare(msg.chatRef(), fileID), caption, opts)
+}
+
+// UploadAudio sends file as audio, uploading it if needed, as a reply to this
+// message.
+func (msg *Message) UploadAudio(file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return msg.sendAudio(tgbotapi.NewAudio(msg.chatRef(), file), caption, opts)
+}
+
+// VideoReply sends an already-uploaded video as a reply to this message.
+func (msg *Message) VideoReply(fileID, caption string, opts ...MediaOption) Sendable {
+	return msg.sendVideo(tgbotapi.NewVideoShare(msg.chatRef(), fileID), caption, opts)
+}
+
+// UploadVideo sends file as a video, uploading it if needed, as a reply to
+// this message.
+func (msg *Message) UploadVideo(file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return msg.sendVideo(tgbotapi.NewVideo(msg.chatRef(), file), caption, opts)
+}
+
+// VoiceReply sends an already-uploaded voice message as a reply to this
+// message.
+func (msg *Message) VoiceReply(fileID, caption string, opts ...MediaOption) Sendable {
+	return msg.sendVoice(tgbotapi.NewVoiceShare(msg.chatRef(), fileID), caption, opts)
+}
+
+// UploadVoice sends file, an .ogg encoded with OPUS, as a voice message,
+// uploading it if needed, as a reply to this message.
+func (msg *Message) UploadVoice(file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return msg.sendVoice(tgbotapi.NewVoice(msg.chatRef(), file), caption, opts)
+}
+
+// AnimationReply sends an already-uploaded animation as a reply to this
+// message.
+func (msg *Message) AnimationReply(fileID, caption string, opts ...MediaOption) Sendable {
+	return msg.sendAnimation(tgbotapi.NewAnimationShare(msg.chatRef(), fileID), caption, opts)
+}
+
+// UploadAnimation sends file, a GIF or silent video, as an animation,
+// uploading it if needed, as a reply to this message.
+func (msg *Message) UploadAnimation(file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return msg.sendAnimation(tgbotapi.NewAnimation(msg.chatRef(), file), caption, opts)
+}
+
+// VideoNoteReply sends an already-uploaded video note as a reply to this
+// message.
+func (msg *Message) VideoNoteReply(fileID string, opts ...MediaOption) Sendable {
+	return msg.sendVideoNote(tgbotapi.NewVideoNoteShare(msg.chatRef(), fileID), opts)
+}
+
+// UploadVideoNote sends file, a square video, as a video note, uploading it
+// if needed, as a reply to this message.
+func (msg *Message) UploadVideoNote(file tgbotapi.InputFile, opts ...MediaOption) Uploadable {
+	return msg.sendVideoNote(tgbotapi.NewVideoNote(msg.chatRef(), file), opts)
+}
+
+// LocationReply replies to this message with a point on the map.
+func (msg *Message) LocationReply(latitude, longitude float64) Sendable {
+	config := tgbotapi.NewLocation(msg.chatRef(), latitude, longitude)
+	config.SendOptions = msg.replyOptions()
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendLocation(config)
+	})
+}
+
+// ContactReply replies to this message with a phone contact.
+func (msg *Message) ContactReply(phoneNumber, firstName, lastName string) Sendable {
+	config := tgbotapi.NewContact(msg.chatRef(), phoneNumber, firstName)
+	config.SendOptions = msg.replyOptions()
+	config.LastName = lastName
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendContact(config)
+	})
+}
+
+// VenueReply replies to this message with a named and addressed place.
+func (msg *Message) VenueReply(latitude, longitude float64, title, address string) Sendable {
+	config := tgbotapi.NewVenue(msg.chatRef(), latitude, longitude, title, address)
+	config.SendOptions = msg.replyOptions()
+
+	return msg.reply(func() (tgbotapi.Message, error) {
+		return msg.bot.api.SendVenue(config)
+	})
+}
+
+// Photo sends an already-uploaded photo to the specified chat.
+func (bot *Bot) Photo(chat tgbotapi.ChatRef, fileID, caption string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").PhotoReply(fileID, caption, opts...)
+}
+
+// UploadPhoto uploads a new photo to the service, and sends it to the
+// specified chat.
+func (bot *Bot) UploadPhoto(chat tgbotapi.ChatRef, r io.Reader, caption string, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadPhoto(r, caption, opts...)
+}
+
+// Sticker sends a sticker, as defined by fileID, to the specified chat.
+func (bot *Bot) Sticker(chat tgbotapi.ChatRef, fileID string) Sendable {
+	return bot.Message(chat, "").StickerReply(fileID)
+}
+
+// Document sends an already-uploaded document to the specified chat.
+func (bot *Bot) Document(chat tgbotapi.ChatRef, fileID, caption string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").DocumentReply(fileID, caption, opts...)
+}
+
+// UploadDocument sends file as a document, uploading it if needed, to the
+// specified chat.
+func (bot *Bot) UploadDocument(chat tgbotapi.ChatRef, file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadDocument(file, caption, opts...)
+}
+
+// Audio sends an already-uploaded audio file to the specified chat.
+func (bot *Bot) Audio(chat tgbotapi.ChatRef, fileID, caption string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").AudioReply(fileID, caption, opts...)
+}
+
+// UploadAudio sends file as audio, uploading it if needed, to the specified
+// chat.
+func (bot *Bot) UploadAudio(chat tgbotapi.ChatRef, file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadAudio(file, caption, opts...)
+}
+
+// Video sends an already-uploaded video to the specified chat.
+func (bot *Bot) Video(chat tgbotapi.ChatRef, fileID, caption string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").VideoReply(fileID, caption, opts...)
+}
+
+// UploadVideo sends file as a video, uploading it if needed, to the specified
+// chat.
+func (bot *Bot) UploadVideo(chat tgbotapi.ChatRef, file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadVideo(file, caption, opts...)
+}
+
+// Voice sends an already-uploaded voice message to the specified chat.
+func (bot *Bot) Voice(chat tgbotapi.ChatRef, fileID, caption string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").VoiceReply(fileID, caption, opts...)
+}
+
+// UploadVoice sends file as a voice message, uploading it if needed, to the
+// specified chat.
+func (bot *Bot) UploadVoice(chat tgbotapi.ChatRef, file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadVoice(file, caption, opts...)
+}
+
+// Animation sends an already-uploaded animation to the specified chat.
+func (bot *Bot) Animation(chat tgbotapi.ChatRef, fileID, caption string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").AnimationReply(fileID, caption, opts...)
+}
+
+// UploadAnimation sends file as an animation, uploading it if needed, to the
+// specified chat.
+func (bot *Bot) UploadAnimation(chat tgbotapi.ChatRef, file tgbotapi.InputFile, caption string, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadAnimation(file, caption, opts...)
+}
+
+// VideoNote sends an already-uploaded video note to the specified chat.
+func (bot *Bot) VideoNote(chat tgbotapi.ChatRef, fileID string, opts ...MediaOption) Sendable {
+	return bot.Message(chat, "").VideoNoteReply(fileID, opts...)
+}
+
+// UploadVideoNote sends file as a video note, uploading it if needed, to the
+// specified chat.
+func (bot *Bot) UploadVideoNote(chat tgbotapi.ChatRef, file tgbotapi.InputFile, opts ...MediaOption) Uploadable {
+	return bot.Message(chat, "").UploadVideoNote(file, opts...)
+}
+
+// Location sends a point on the map to the specified chat.
+func (bot *Bot) Location(chat tgbotapi.ChatRef, latitude, longitude float64) Sendable {
+	return bot.Message(chat, "").LocationReply(latitude, longitude)
+}
+
+// Contact sends a phone contact to the specified chat.
+func (bot *Bot) Contact(chat tgbotapi.ChatRef, phoneNumber, firstName, lastName string) Sendable {
+	return bot.Message(chat, "").ContactReply(phoneNumber, firstName, lastName)
+}
+
+// Venue sends a named and addressed place to the specified chat.
+func (bot *Bot) Venue(chat tgbotapi.ChatRef, latitude, longitude float64, title, address string) Sendable {
+	return bot.Message(chat, "").VenueReply(latitude, longitude, title, address)
+}
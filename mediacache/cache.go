@@ -0,0 +1,432 @@
+// Package mediacache keeps files downloaded from Telegram on disk, so files a
+// bot sees over and over, such as stickers, are only fetched once.
+package mediacache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// ErrCorrupt is returned when reading a cached file which doesn't match what
+// was downloaded. The file is dropped from the cache, so opening it again
+// downloads it afresh.
+var ErrCorrupt = errors.New("mediacache: cached file failed its integrity check")
+
+// maxAttempts is how many times Open downloads a file which keeps failing
+// its integrity check before giving up.
+const maxAttempts = 3
+
+// Cache is a directory of files downloaded from Telegram, keyed by their
+// FileUniqueID, which stays under a maximum size by evicting the least
+// recently used files. It is safe for concurrent use, and only downloads
+// each file once however many goroutines ask for it at the same time.
+type Cache struct {
+	api     *tgbotapi.BotAPI
+	dir     string
+	maxSize int64
+
+	lock     sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	size     int64
+	inflight map[string]*download
+
+	// uniqueIDs are the FileUniqueIDs of the FileIDs looked up with GetFile,
+	// so files opened by FileID alone are found without asking again.
+	uniqueIDs map[string]string
+}
+
+// entry is a file in the cache, and the sidecar stored alongside it.
+type entry struct {
+	UniqueID string `json:"file_unique_id"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// download is a fetch of a file which other callers can wait on.
+type download struct {
+	done chan struct{}
+	err  error
+}
+
+// New opens the cache in dir, creating it if needed, and picks up the files
+// already in it. maxSize is the total size of the files to keep, in bytes,
+// or 0 for no limit.
+func New(api *tgbotapi.BotAPI, dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	cache := &Cache{
+		api:     api,
+		dir:     dir,
+		maxSize: maxSize,
+
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		inflight:  make(map[string]*download),
+		uniqueIDs: make(map[string]string),
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	type found struct {
+		entry *entry
+		used  time.Time
+	}
+	var files []found
+
+	for _, name := range names {
+		switch {
+		case strings.HasSuffix(name, ".tmp"):
+			// Left over from a download which never finished.
+			os.Remove(name)
+			continue
+		case !strings.HasSuffix(name, ".json"):
+			continue
+		}
+
+		e, err := cache.readEntry(name)
+		if err != nil {
+			os.Remove(name)
+			continue
+		}
+
+		info, err := os.Stat(cache.path(e.UniqueID))
+		if err != nil || info.Size() != e.Size {
+			cache.removeFiles(e.UniqueID)
+			continue
+		}
+
+		files = append(files, found{e, info.ModTime()})
+	}
+
+	// Files are touched as they are opened, so the oldest are the least
+	// recently used.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].used.After(files[j].used)
+	})
+
+	for _, file := range files {
+		cache.entries[file.entry.UniqueID] = cache.lru.PushBack(file.entry)
+		cache.size += file.entry.Size
+	}
+
+	cache.lock.Lock()
+	cache.evict()
+	cache.lock.Unlock()
+
+	return cache, nil
+}
+
+// Open returns the contents of a file, downloading it into the cache first if
+// it isn't already there. uniqueID is the file's FileUniqueID; if it is empty,
+// it is looked up with GetFile, the first time the file is opened.
+//
+// The returned reader fails with ErrCorrupt at the end of the file if its
+// contents aren't what was downloaded.
+func (cache *Cache) Open(ctx context.Context, fileID, uniqueID string) (io.ReadCloser, error) {
+	var file *tgbotapi.File
+	if uniqueID == "" {
+		cache.lock.Lock()
+		uniqueID = cache.uniqueIDs[fileID]
+		cache.lock.Unlock()
+	}
+	if uniqueID == "" {
+		f, err := cache.api.GetFileContext(ctx, tgbotapi.NewFile(fileID))
+		if err != nil {
+			return nil, err
+		}
+
+		file = &f
+		uniqueID = f.FileUniqueID
+	}
+
+	if !validID(uniqueID) {
+		return nil, fmt.Errorf("mediacache: invalid file unique ID %q", uniqueID)
+	}
+
+	for attempt := 0; attempt < maxAttempts; {
+		cache.lock.Lock()
+
+		if elem, ok := cache.entries[uniqueID]; ok {
+			cache.lru.MoveToFront(elem)
+			cache.uniqueIDs[fileID] = uniqueID
+			cache.lock.Unlock()
+
+			r, err := cache.openEntry(elem.Value.(*entry))
+			if err == nil {
+				return r, nil
+			}
+
+			cache.remove(uniqueID)
+			continue
+		}
+
+		if dl, ok := cache.inflight[uniqueID]; ok {
+			cache.lock.Unlock()
+
+			select {
+			case <-dl.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+
+			// If the caller downloading it gave up, the download is
+			// started again for this one.
+			if dl.err != nil && !isContextErr(dl.err) {
+				return nil, dl.err
+			}
+			continue
+		}
+
+		dl := &download{done: make(chan struct{})}
+		cache.inflight[uniqueID] = dl
+		cache.lock.Unlock()
+
+		dl.err = cache.fetch(ctx, fileID, uniqueID, file)
+		attempt++
+
+		cache.lock.Lock()
+		delete(cache.inflight, uniqueID)
+		cache.lock.Unlock()
+		close(dl.done)
+
+		if dl.err != nil {
+			return nil, dl.err
+		}
+	}
+
+	return nil, ErrCorrupt
+}
+
+// isContextErr returns true if err is a context being cancelled or timing
+// out, rather than the download itself failing.
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// fetch downloads a file into the cache. file is the result of GetFile for
+// it, if that is already known.
+func (cache *Cache) fetch(ctx context.Context, fileID, uniqueID string, file *tgbotapi.File) error {
+	tmp, err := ioutil.TempFile(cache.dir, uniqueID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	w := &countingWriter{w: io.MultiWriter(tmp, h)}
+
+	if file != nil {
+		err = cache.api.DownloadFile(ctx, *file, w)
+	} else {
+		err = cache.api.Download(ctx, fileID, w)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if file != nil && file.FileSize != 0 && int64(file.FileSize) != w.n {
+		return fmt.Errorf("mediacache: downloaded %d bytes of %s, expected %d", w.n, fileID, file.FileSize)
+	}
+
+	e := &entry{
+		UniqueID: uniqueID,
+		Size:     w.n,
+		SHA256:   hex.EncodeToString(h.Sum(nil)),
+	}
+
+	if err := os.Rename(tmp.Name(), cache.path(uniqueID)); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(cache.path(uniqueID)+".json", data, 0600); err != nil {
+		os.Remove(cache.path(uniqueID))
+		return err
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.entries[uniqueID] = cache.lru.PushFront(e)
+	cache.uniqueIDs[fileID] = uniqueID
+	cache.size += e.Size
+	cache.evict()
+
+	return nil
+}
+
+// openEntry opens a cached file for reading, checking it as it is read.
+func (cache *Cache) openEntry(e *entry) (io.ReadCloser, error) {
+	path := cache.path(e.UniqueID)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil || info.Size() != e.Size {
+		f.Close()
+		return nil, ErrCorrupt
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return &verifyingReader{
+		file: f,
+
+		hash: sha256.New(),
+		want: e.SHA256,
+		corrupt: func() {
+			cache.remove(e.UniqueID)
+		},
+	}, nil
+}
+
+// evict removes the least recently used files until the cache fits in its
+// maximum size, always keeping the most recent one. The lock must be held.
+func (cache *Cache) evict() {
+	for cache.maxSize > 0 && cache.size > cache.maxSize && cache.lru.Len() > 1 {
+		e := cache.lru.Remove(cache.lru.Back()).(*entry)
+
+		delete(cache.entries, e.UniqueID)
+		cache.size -= e.Size
+		cache.removeFiles(e.UniqueID)
+	}
+}
+
+// remove drops a file from the cache.
+func (cache *Cache) remove(uniqueID string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if elem, ok := cache.entries[uniqueID]; ok {
+		cache.lru.Remove(elem)
+		delete(cache.entries, uniqueID)
+		cache.size -= elem.Value.(*entry).Size
+	}
+
+	cache.removeFiles(uniqueID)
+}
+
+// removeFiles deletes a file and its sidecar, and forgets the FileIDs it was
+// opened with. The lock must be held, except while New is loading.
+func (cache *Cache) removeFiles(uniqueID string) {
+	os.Remove(cache.path(uniqueID))
+	os.Remove(cache.path(uniqueID) + ".json")
+
+	for fileID, id := range cache.uniqueIDs {
+		if id == uniqueID {
+			delete(cache.uniqueIDs, fileID)
+		}
+	}
+}
+
+func (cache *Cache) readEntry(name string) (*entry, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	if !validID(e.UniqueID) {
+		return nil, fmt.Errorf("mediacache: invalid file unique ID %q", e.UniqueID)
+	}
+
+	return &e, nil
+}
+
+func (cache *Cache) path(uniqueID string) string {
+	return filepath.Join(cache.dir, uniqueID)
+}
+
+// validID returns true if uniqueID is safe to use as a file name. Telegram's
+// IDs are URL-safe base64.
+func validID(uniqueID string) bool {
+	if uniqueID == "" {
+		return false
+	}
+
+	for _, c := range uniqueID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
+
+// verifyingReader hashes a cached file as it is read, and fails at the end of
+// it if the hash doesn't match. It only offers Read and Close, so nothing,
+// such as io.Copy, can get at the file without the check.
+type verifyingReader struct {
+	file *os.File
+
+	hash    hash.Hash
+	want    string
+	corrupt func()
+}
+
+func (vr *verifyingReader) Read(p []byte) (int, error) {
+	n, err := vr.file.Read(p)
+	vr.hash.Write(p[:n])
+
+	if err == io.EOF && hex.EncodeToString(vr.hash.Sum(nil)) != vr.want {
+		vr.corrupt()
+		return n, ErrCorrupt
+	}
+
+	return n, err
+}
+
+func (vr *verifyingReader) Close() error {
+	return vr.file.Close()
+}
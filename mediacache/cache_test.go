@@ -0,0 +1,312 @@
+package mediacache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+const testToken = "123:secret"
+
+// fakeTelegram serves getFile and file downloads for files named by their
+// FileID, whose FileUniqueID is "u" followed by the FileID.
+type fakeTelegram struct {
+	files map[string][]byte
+
+	lock      sync.Mutex
+	getFiles  int
+	downloads map[string]int
+
+	// gate, if set, holds up downloads until it is closed.
+	gate chan struct{}
+	// started has a value sent to it as each download starts, if set.
+	started chan string
+}
+
+func (fake *fakeTelegram) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case req.URL.Path == "/bot"+testToken+"/getFile":
+		body, _ := ioutil.ReadAll(req.Body)
+		v, _ := url.ParseQuery(string(body))
+		fileID := v.Get("file_id")
+
+		fake.lock.Lock()
+		fake.getFiles++
+		fake.lock.Unlock()
+
+		data, ok := fake.files[fileID]
+		if !ok {
+			return respond(http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"Bad Request: wrong file_id"}`), nil
+		}
+
+		return respond(http.StatusOK, fmt.Sprintf(`{"ok":true,"result":{"file_id":%q,"file_unique_id":%q,"file_size":%d,"file_path":%q}}`,
+			fileID, "u"+fileID, len(data), "files/"+fileID)), nil
+
+	case strings.HasPrefix(req.URL.Path, "/file/bot"+testToken+"/files/"):
+		fileID := strings.TrimPrefix(req.URL.Path, "/file/bot"+testToken+"/files/")
+
+		fake.lock.Lock()
+		fake.downloads[fileID]++
+		fake.lock.Unlock()
+
+		if fake.started != nil {
+			fake.started <- fileID
+		}
+		if fake.gate != nil {
+			select {
+			case <-fake.gate:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		return respond(http.StatusOK, string(fake.files[fileID])), nil
+	}
+
+	return respond(http.StatusNotFound, `{"ok":false,"error_code":404,"description":"Not Found"}`), nil
+}
+
+func (fake *fakeTelegram) count(fileID string) int {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	return fake.downloads[fileID]
+}
+
+func respond(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+// newTestCache returns a cache talking to a fake Telegram serving files.
+func newTestCache(t *testing.T, maxSize int64, files map[string][]byte) (*Cache, *fakeTelegram) {
+	fake := &fakeTelegram{
+		files:     files,
+		downloads: make(map[string]int),
+	}
+
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = fake
+	t.Cleanup(func() {
+		http.DefaultClient.Transport = transport
+	})
+
+	dir, err := ioutil.TempDir("", "mediacache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	cache, err := New(&tgbotapi.BotAPI{Token: testToken}, dir, maxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cache, fake
+}
+
+func readAll(t *testing.T, cache *Cache, ctx context.Context, fileID, uniqueID string) ([]byte, error) {
+	t.Helper()
+
+	r, err := cache.Open(ctx, fileID, uniqueID)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, r)
+
+	return buf.Bytes(), err
+}
+
+func TestOpenHit(t *testing.T) {
+	cache, fake := newTestCache(t, 0, map[string][]byte{
+		"a": []byte("sticker"),
+	})
+
+	for i := 0; i < 3; i++ {
+		data, err := readAll(t, cache, context.Background(), "a", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "sticker" {
+			t.Errorf("got %q, want %q", data, "sticker")
+		}
+	}
+
+	if n := fake.count("a"); n != 1 {
+		t.Errorf("downloaded %d times, want once", n)
+	}
+	if fake.getFiles != 1 {
+		t.Errorf("called getFile %d times, want once", fake.getFiles)
+	}
+}
+
+func TestOpenEvicts(t *testing.T) {
+	cache, fake := newTestCache(t, 10, map[string][]byte{
+		"a": []byte("aaaa"),
+		"b": []byte("bbbb"),
+		"c": []byte("cccc"),
+	})
+
+	for _, fileID := range []string{"a", "b", "a", "c"} {
+		if _, err := readAll(t, cache, context.Background(), fileID, "u"+fileID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// b was used least recently, so made way for c.
+	if _, err := os.Stat(filepath.Join(cache.dir, "ub")); !os.IsNotExist(err) {
+		t.Errorf("b is still on disk: %v", err)
+	}
+	if cache.size != 8 {
+		t.Errorf("cache holds %d bytes, want 8", cache.size)
+	}
+
+	if _, err := readAll(t, cache, context.Background(), "a", "ua"); err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.count("a"); n != 1 {
+		t.Errorf("a downloaded %d times, want once", n)
+	}
+
+	if _, err := readAll(t, cache, context.Background(), "b", "ub"); err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.count("b"); n != 2 {
+		t.Errorf("b downloaded %d times, want twice", n)
+	}
+}
+
+func TestOpenCorrupt(t *testing.T) {
+	cache, fake := newTestCache(t, 0, map[string][]byte{
+		"a": []byte("original"),
+	})
+
+	if _, err := readAll(t, cache, context.Background(), "a", "ua"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tamper with the file, keeping its size.
+	if err := ioutil.WriteFile(filepath.Join(cache.dir, "ua"), []byte("tampered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := cache.Open(context.Background(), "a", "ua")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.Copy(ioutil.Discard, r)
+	r.Close()
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("io.Copy returned %v, want ErrCorrupt", err)
+	}
+
+	data, err := readAll(t, cache, context.Background(), "a", "ua")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "original" {
+		t.Errorf("got %q, want %q", data, "original")
+	}
+	if n := fake.count("a"); n != 2 {
+		t.Errorf("downloaded %d times, want twice", n)
+	}
+}
+
+func TestOpenSharesDownloads(t *testing.T) {
+	cache, fake := newTestCache(t, 0, map[string][]byte{
+		"a": []byte("shared"),
+	})
+	fake.gate = make(chan struct{})
+	fake.started = make(chan string, 10)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			data, err := readAll(t, cache, context.Background(), "a", "ua")
+			if err == nil && string(data) != "shared" {
+				err = fmt.Errorf("got %q", data)
+			}
+			errs <- err
+		}()
+	}
+
+	<-fake.started
+	// Give the others time to start waiting on the download.
+	time.Sleep(50 * time.Millisecond)
+	close(fake.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := fake.count("a"); n != 1 {
+		t.Errorf("downloaded %d times, want once", n)
+	}
+}
+
+func TestOpenCancelledDownloader(t *testing.T) {
+	cache, fake := newTestCache(t, 0, map[string][]byte{
+		"a": []byte("shared"),
+	})
+	fake.gate = make(chan struct{})
+	fake.started = make(chan string, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := readAll(t, cache, ctx, "a", "ua")
+		first <- err
+	}()
+	<-fake.started
+
+	second := make(chan error, 1)
+	go func() {
+		data, err := readAll(t, cache, context.Background(), "a", "ua")
+		if err == nil && string(data) != "shared" {
+			err = fmt.Errorf("got %q", data)
+		}
+		second <- err
+	}()
+
+	// Let the second caller start waiting, then give up on the first.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller got %v, want context.Canceled", err)
+	}
+
+	<-fake.started
+	close(fake.gate)
+	if err := <-second; err != nil {
+		t.Errorf("second caller got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package telegram
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AlbumItems returns each part of the album this message was received as,
+// in the order they were sent, or nil if it isn't an album. The message
+// itself is the part which carries the album's caption, or the first part
+// if none does.
+func (msg Message) AlbumItems() []Message {
+	return msg.album
+}
+
+// IsAlbum returns true if this message is an album of several photos or
+// videos.
+func (msg Message) IsAlbum() bool {
+	return len(msg.album) > 0
+}
+
+// albumCollector holds back the parts of incoming albums until no more have
+// arrived for the quiet period, then delivers each album as one message.
+type albumCollector struct {
+	quiet   time.Duration
+	deliver func(Message)
+
+	lock    sync.Mutex
+	pending map[string]*pendingAlbum
+}
+
+type pendingAlbum struct {
+	items []Message
+	timer *time.Timer
+}
+
+func newAlbumCollector(quiet time.Duration, deliver func(Message)) *albumCollector {
+	return &albumCollector{
+		quiet:   quiet,
+		deliver: deliver,
+
+		pending: make(map[string]*pendingAlbum),
+	}
+}
+
+// add holds back msg if it is part of an album, returning false if it should
+// be delivered straight away instead.
+func (collector *albumCollector) add(msg Message) bool {
+	if msg.MediaGroupID == "" || collector.quiet <= 0 {
+		return false
+	}
+
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	// Albums are keyed by chat too, as their IDs are only unique to one.
+	key := msg.chatRef().String() + ":" + msg.MediaGroupID
+
+	album, ok := collector.pending[key]
+	if !ok {
+		album = &pendingAlbum{}
+		album.timer = time.AfterFunc(collector.quiet, func() {
+			collector.flush(key)
+		})
+
+		collector.pending[key] = album
+	} else {
+		album.timer.Reset(collector.quiet)
+	}
+
+	album.items = append(album.items, msg)
+
+	return true
+}
+
+// flush delivers an album once it has been quiet.
+func (collector *albumCollector) flush(key string) {
+	collector.lock.Lock()
+	album, ok := collector.pending[key]
+	delete(collector.pending, key)
+	collector.lock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	collector.deliver(albumMessage(album.items))
+}
+
+// albumMessage gathers the parts of an album into one message.
+func albumMessage(items []Message) Message {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].MessageID < items[j].MessageID
+	})
+
+	msg := items[0]
+	for _, item := range items {
+		if item.Caption != "" {
+			msg = item
+			break
+		}
+	}
+
+	msg.album = items
+
+	return msg
+}
@@ -0,0 +1,208 @@
+package telegram
+
+import (
+	"sync"
+
+	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/keyboard"
+)
+
+// MenuItem is a reply keyboard button which runs Handle when pressed. Only
+// buttons which send their text can be handled; those requesting a contact,
+// location and so on send that instead, so need no handler.
+type MenuItem struct {
+	tgbotapi.KeyboardButton
+
+	Handle func(Message)
+}
+
+// Item makes a menu item which runs handle when pressed.
+func Item(text string, handle func(Message)) MenuItem {
+	return MenuItem{
+		KeyboardButton: tgbotapi.KeyboardButton{Text: text},
+		Handle:         handle,
+	}
+}
+
+// Menu is a reply keyboard whose buttons run handlers when pressed, shown
+// with Message.ShowMenu. Build it once and show it as often as needed.
+type Menu struct {
+	mods     []keyboard.Modifier
+	handlers map[string]func(Message)
+}
+
+// NewMenu creates an empty menu, with modifiers such as keyboard.Resize.
+func NewMenu(mods ...keyboard.Modifier) *Menu {
+	return &Menu{
+		mods:     mods,
+		handlers: make(map[string]func(Message)),
+	}
+}
+
+// Row adds a row of items to the menu.
+func (menu *Menu) Row(items ...MenuItem) *Menu {
+	return menu.add(keyboard.ButtonRow, items)
+}
+
+// Grid adds items to the menu in rows of columns items, as keyboard.Grid.
+func (menu *Menu) Grid(columns int, items ...MenuItem) *Menu {
+	return menu.add(func(buttons ...tgbotapi.KeyboardButton) keyboard.Modifier {
+		return keyboard.Grid(columns, buttons...)
+	}, items)
+}
+
+// Wrap adds items to the menu in rows up to width characters wide, as
+// keyboard.Wrap.
+func (menu *Menu) Wrap(width int, items ...MenuItem) *Menu {
+	return menu.add(func(buttons ...tgbotapi.KeyboardButton) keyboard.Modifier {
+		return keyboard.Wrap(width, buttons...)
+	}, items)
+}
+
+func (menu *Menu) add(layout func(...tgbotapi.KeyboardButton) keyboard.Modifier, items []MenuItem) *Menu {
+	buttons := make([]tgbotapi.KeyboardButton, len(items))
+	for i, item := range items {
+		buttons[i] = item.KeyboardButton
+
+		if item.Handle != nil {
+			menu.handlers[item.Text] = item.Handle
+		}
+	}
+
+	menu.mods = append(menu.mods, layout(buttons...))
+
+	return menu
+}
+
+// ShowMenu presents menu to the user when responding to this message. If the
+// menu is keyboard.Selective and this message is a reply, only the user
+// being replied to can use it; otherwise anyone in the chat can.
+func (msg *Message) ShowMenu(menu *Menu) Sendable {
+	if msg.dir != outgoing {
+		return msg
+	}
+
+	msg.opts.ReplyMarkup = keyboard.New(menu.mods...)
+	msg.menu = menu
+
+	return msg
+}
+
+// menuKey identifies who a menu was shown to. User is 0 for menus shown to
+// the whole chat.
+type menuKey struct {
+	Chat int64
+	User int64
+}
+
+// MenuRouter finds the handlers for reply keyboard buttons. It remembers the
+// menu last shown to each chat and user, and has handlers for buttons with
+// particular labels wherever they appear.
+type MenuRouter struct {
+	lock   sync.Mutex
+	labels map[string]func(Message)
+	shown  map[menuKey]*Menu
+}
+
+// Handle registers handler for buttons labelled label, in any keyboard.
+// Buttons in menus shown with ShowMenu take precedence.
+func (router *MenuRouter) Handle(label string, handler func(Message)) {
+	router.lock.Lock()
+	defer router.lock.Unlock()
+
+	if router.labels == nil {
+		router.labels = make(map[string]func(Message))
+	}
+
+	router.labels[label] = handler
+}
+
+// sent updates the menu shown to whoever msg was sent to, after it was sent.
+// Other reply keyboards, and removing the keyboard, replace the menu.
+func (router *MenuRouter) sent(msg Message) {
+	key := menuKey{Chat: msg.Chat.ID}
+
+	switch markup := msg.opts.ReplyMarkup.(type) {
+	case tgbotapi.ReplyKeyboardMarkup:
+		if markup.Selective && msg.ReplyToMessage != nil {
+			key.User = msg.ReplyToMessage.From.ID
+		}
+	case tgbotapi.ReplyKeyboardRemove:
+		if markup.Selective && msg.ReplyToMessage != nil {
+			key.User = msg.ReplyToMessage.From.ID
+		}
+	default:
+		return
+	}
+
+	router.lock.Lock()
+	defer router.lock.Unlock()
+
+	// Keyboards for the whole chat replace those shown to people in it.
+	if key.User == 0 {
+		for shown := range router.shown {
+			if shown.Chat == key.Chat {
+				delete(router.shown, shown)
+			}
+		}
+	}
+
+	if msg.menu == nil {
+		delete(router.shown, key)
+		return
+	}
+
+	if router.shown == nil {
+		router.shown = make(map[menuKey]*Menu)
+	}
+
+	router.shown[key] = msg.menu
+}
+
+// match returns the handler for the button msg's text is from, or nil.
+func (router *MenuRouter) match(msg Message) func(Message) {
+	if msg.Text == "" {
+		return nil
+	}
+
+	router.lock.Lock()
+	defer router.lock.Unlock()
+
+	for _, key := range []menuKey{
+		{Chat: msg.Chat.ID, User: msg.From.ID},
+		{Chat: msg.Chat.ID},
+	} {
+		if menu, ok := router.shown[key]; ok {
+			if handler, ok := menu.handlers[msg.Text]; ok {
+				return handler
+			}
+		}
+	}
+
+	return router.labels[msg.Text]
+}
+
+// HandleButton registers handler for reply keyboard buttons labelled label,
+// in the bot's MenuRouter.
+func (bot *Bot) HandleButton(label string, handler func(Message)) {
+	bot.Menus.Handle(label, handler)
+}
+
+// DispatchButton passes msg to the handler for the reply keyboard button it
+// is a press of, and returns false if it isn't one. Handlers are run in a
+// goroutine of their own. GetMessages and MessagesChan do this for every
+// message they receive.
+func (bot *Bot) DispatchButton(msg Message) bool {
+	handler := bot.Menus.match(msg)
+	if handler == nil {
+		return false
+	}
+
+	runHandler(func() {
+		handler(msg)
+	}, func() {
+		msg.ReplyWith("Fatal bot error. Sorry!").Send()
+	})
+
+	return true
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/keyboard"
+)
+
+func TestMenuReplacesSelective(t *testing.T) {
+	var router MenuRouter
+	var pressed string
+
+	chat := tgbotapi.UserOrGroupChat{ID: -100}
+	user := tgbotapi.User{ID: 7}
+
+	show := func(menu *Menu, replyTo *tgbotapi.Message) {
+		msg := &Message{
+			Message: tgbotapi.Message{Chat: chat, ReplyToMessage: replyTo},
+			dir:     outgoing,
+		}
+		msg.ShowMenu(menu)
+		router.sent(*msg)
+	}
+
+	show(NewMenu(keyboard.Selective).Row(Item("Yes", func(Message) { pressed = "old" })),
+		&tgbotapi.Message{From: user})
+	show(NewMenu().Row(Item("Yes", func(Message) { pressed = "new" })), nil)
+
+	handler := router.match(Message{
+		Message: tgbotapi.Message{Chat: chat, From: user, Text: "Yes"},
+		dir:     incoming,
+	})
+	if handler == nil {
+		t.Fatal("button from the menu shown to the chat wasn't matched")
+	}
+
+	handler(Message{})
+	if pressed != "new" {
+		t.Errorf("pressed the %s menu's button, want the new one", pressed)
+	}
+}
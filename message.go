@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/AmandaCameron/go-telegram/api"
 	"github.com/AmandaCameron/go-telegram/keyboard"
@@ -18,10 +19,13 @@ const (
 type Message struct {
 	tgbotapi.Message
 
-	context               map[string]interface{}
-	replyID               int32
-	replyMarkup           interface{}
-	disableWebPagePreview bool
+	context map[string]interface{}
+	opts    tgbotapi.SendOptions
+	album   []Message
+	menu    *Menu
+
+	ttl           time.Duration
+	deleteCommand bool
 
 	bot *Bot
 	dir direction
@@ -67,15 +71,90 @@ func (msg Message) IsGroup() bool {
 	return (msg.Message.Chat.FirstName == "" && msg.Message.Chat.LastName == "")
 }
 
-// Message creates a new outbound message to the specified chatID and with
-// the specified printf-formatted body
-func (bot *Bot) Message(chatID int32, f string, args ...interface{}) *Message {
+// WithOptions returns a copy of the message which applies opts to everything
+// sent from it: the message itself if it is outbound, or any replies to it.
+// Only the options set in opts are changed; those set earlier, such as with
+// Silent or by ReplyWith, are kept.
+func (msg Message) WithOptions(opts tgbotapi.SendOptions) *Message {
+	if opts.MessageThreadID != 0 {
+		msg.opts.MessageThreadID = opts.MessageThreadID
+	}
+	if opts.ReplyToMessageID != 0 {
+		msg.opts.ReplyToMessageID = opts.ReplyToMessageID
+	}
+	if opts.AllowSendingWithoutReply {
+		msg.opts.AllowSendingWithoutReply = true
+	}
+	if opts.ReplyParameters != nil {
+		msg.opts.ReplyParameters = opts.ReplyParameters
+	}
+	if opts.DisableNotification {
+		msg.opts.DisableNotification = true
+	}
+	if opts.ProtectContent {
+		msg.opts.ProtectContent = true
+	}
+	if opts.LinkPreviewOptions != nil {
+		msg.opts.LinkPreviewOptions = opts.LinkPreviewOptions
+	}
+	if opts.ReplyMarkup != nil {
+		msg.opts.ReplyMarkup = opts.ReplyMarkup
+	}
+
+	return &msg
+}
+
+// Silent sends the message, or replies to it, without a notification.
+func (msg *Message) Silent() *Message {
+	msg.opts.DisableNotification = true
+
+	return msg
+}
+
+// Protected prevents the message, or replies to it, from being forwarded or
+// saved.
+func (msg *Message) Protected() *Message {
+	msg.opts.ProtectContent = true
+
+	return msg
+}
+
+// replyOptions returns the options for something sent in response to msg.
+// Replies to incoming messages thread under them, in the same forum topic.
+func (msg Message) replyOptions() tgbotapi.SendOptions {
+	opts := msg.opts
+
+	if msg.dir == incoming {
+		opts.ReplyToMessageID = msg.MessageID
+
+		if opts.MessageThreadID == 0 {
+			opts.MessageThreadID = msg.MessageThreadID
+		}
+	}
+
+	return opts
+}
+
+// chatRef returns the chat the message was sent in, or is to be sent to.
+func (msg Message) chatRef() tgbotapi.ChatRef {
+	if msg.Chat.ID == 0 && msg.Chat.UserName != "" {
+		return tgbotapi.ChatUsername(msg.Chat.UserName)
+	}
+
+	return tgbotapi.ChatID(msg.Chat.ID)
+}
+
+// Message creates a new outbound message to the specified chat and with
+// the specified printf-formatted body. The chat may be given by ID, or by
+// username for public channels.
+func (bot *Bot) Message(chat tgbotapi.ChatRef, f string, args ...interface{}) *Message {
 	return &Message{
 		Message: tgbotapi.Message{
 			Text: fmt.Sprintf(f, args...),
 
 			Chat: tgbotapi.UserOrGroupChat{
-				ID: chatID,
+				ID:       chat.ID,
+				UserName: chat.Username,
 			},
 		},
 
@@ -85,6 +164,23 @@ func (bot *Bot) Message(chatID int32, f string, args ...interface{}) *Message {
 	}
 }
 
+// sent wraps a message the bot has sent.
+func (bot *Bot) sent(message tgbotapi.Message) Message {
+	msg := Message{
+		Message: message,
+
+		context: make(map[string]interface{}),
+		bot:     bot,
+		dir:     outgoing,
+	}
+
+	if message.ReplyMarkup != nil {
+		msg.opts.ReplyMarkup = *message.ReplyMarkup
+	}
+
+	return msg
+}
+
 // ReplyWith generates an outbound reply message, with the formatted string
 // from `f` and it's arguments.
 func (msg Message) ReplyWith(f string, args ...interface{}) *Message {
@@ -102,13 +198,24 @@ func (msg Message) ReplyWith(f string, args ...interface{}) *Message {
 		},
 
 		context: msg.context,
-		replyID: msg.MessageID,
+		opts:    msg.replyOptions(),
+
+		ttl:           msg.ttl,
+		deleteCommand: msg.deleteCommand,
 
 		bot: msg.bot,
 		dir: outgoing,
 	}
 }
 
+// WithMarkup returns a copy of the message which attaches markup to the
+// message itself if it is outbound, or to any replies to it.
+func (msg Message) WithMarkup(markup tgbotapi.ReplyMarkup) *Message {
+	msg.opts.ReplyMarkup = markup
+
+	return &msg
+}
+
 // HideKeyboard tells Telegram to hide any existing Custom Keyboards.
 // if `selective` is set, it will only go to one user.
 func (msg *Message) HideKeyboard(selective bool) Sendable {
@@ -116,11 +223,7 @@ func (msg *Message) HideKeyboard(selective bool) Sendable {
 		return msg
 	}
 
-	msg.replyMarkup = tgbotapi.ReplyKeyboardHide{
-		HideKeyboard: true,
-
-		Selective: selective,
-	}
+	msg.opts.ReplyMarkup = keyboard.Remove(selective)
 
 	return msg
 }
@@ -132,37 +235,36 @@ func (msg *Message) CustomKeyboard(mods ...keyboard.Modifier) Sendable {
 		return msg
 	}
 
-	markup := tgbotapi.ReplyKeyboardMarkup{}
-
-	for _, mod := range mods {
-		mod(&markup)
-	}
-
-	msg.replyMarkup = markup
+	msg.opts.ReplyMarkup = keyboard.New(mods...)
 
 	return msg
 }
 
 // ForceReply tells Telegram that this message, if responded to, should be forced into a
-
 // reply message.
 func (msg *Message) ForceReply(selective bool) Sendable {
-	msg.replyMarkup = tgbotapi.ForceReply{}
+	msg.opts.ReplyMarkup = keyboard.ForceReply(selective)
 
 	return msg
 }
 
+// Send sends the message, after which it holds the message as sent, so it
+// can be edited or deleted.
 func (msg *Message) Send() error {
-	_, err := msg.bot.api.SendMessage(
+	sent, err := msg.bot.api.SendMessage(
 		tgbotapi.MessageConfig{
-			ChatID: msg.Chat.ID,
-			Text:   msg.Text,
-
-			ReplyMarkup: msg.replyMarkup,
+			SendOptions: msg.opts,
 
-			ReplyToMessageID:      msg.replyID,
-			DisableWebPagePreview: msg.disableWebPagePreview,
+			ChatID: msg.chatRef(),
+			Text:   msg.Text,
 		})
+	if err != nil {
+		return err
+	}
+
+	msg.Message = sent
+	msg.scheduleDeletion(sent)
+	msg.bot.Menus.sent(*msg)
 
-	return err
+	return nil
 }
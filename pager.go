@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"fmt"
+
+	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/keyboard"
+)
+
+// PageSource supplies the items of a paginated list. query is whatever the
+// list is being shown for, such as a search term, and is kept as the user
+// pages through it.
+type PageSource struct {
+	// Count returns how many items there are.
+	Count func(query string) (int, error)
+
+	// Fetch returns the buttons for up to limit items, starting at offset.
+	Fetch func(query string, offset, limit int) ([]tgbotapi.InlineKeyboardButton, error)
+}
+
+// Pager shows lists too long for one message a page at a time, with buttons
+// to move between pages which edit the message in place. Create one with
+// Bot.Pager.
+type Pager struct {
+	bot    *Bot
+	prefix string
+	source PageSource
+
+	// PageSize is how many items are shown on each page.
+	PageSize int
+
+	// Text returns the text of the message showing page of pages, counting
+	// from 0. By default it is "Page 1 of 3" and so on.
+	Text func(query string, page, pages int) string
+}
+
+// pagerState is the payload of a pager's navigation buttons.
+type pagerState struct {
+	Page  int
+	Query string
+}
+
+// Pager creates a pager which shows the items of source, and registers the
+// handler for its navigation buttons. name must be unique among the bot's
+// pagers.
+func (bot *Bot) Pager(name string, source PageSource) *Pager {
+	pager := &Pager{
+		bot:    bot,
+		prefix: "page:" + name + ":",
+		source: source,
+
+		PageSize: 8,
+		Text: func(query string, page, pages int) string {
+			return fmt.Sprintf("Page %d of %d", page+1, pages)
+		},
+	}
+
+	bot.HandleCallbackData(pager.prefix, pagerState{}, pager.navigate)
+
+	return pager
+}
+
+// Show replies to msg with the first page of the list for query.
+func (pager *Pager) Show(msg Message, query string) error {
+	text, markup, err := pager.render(query, 0)
+	if err != nil {
+		return err
+	}
+
+	reply := msg.ReplyWith("%s", text)
+	if reply == nil {
+		reply = pager.bot.Message(msg.chatRef(), "%s", text)
+	}
+
+	return reply.WithMarkup(markup).Send()
+}
+
+// navigate shows the page a navigation button was pressed for.
+func (pager *Pager) navigate(cb *Callback, payload interface{}) {
+	state := payload.(*pagerState)
+
+	text, markup, err := pager.render(state.Query, state.Page)
+	if err != nil {
+		cb.fail(err)
+		return
+	}
+
+	if err := cb.show(text, markup); err != nil {
+		cb.fail(err)
+	}
+}
+
+// render returns the text and keyboard for a page of the list. Pages past
+// the end, such as after items are removed, show the last page instead.
+func (pager *Pager) render(query string, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	count, err := pager.source.Count(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	size := pager.PageSize
+	if size < 1 {
+		size = 1
+	}
+
+	pages := (count + size - 1) / size
+	if pages < 1 {
+		pages = 1
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	items, err := pager.source.Fetch(query, page*size, size)
+	if err != nil {
+		return "", nil, err
+	}
+
+	markup := keyboard.Inline(keyboard.InlineColumn(items...))
+
+	if pages > 1 {
+		var controls []tgbotapi.InlineKeyboardButton
+
+		if page > 0 {
+			prev, err := pager.button("‹", query, page-1)
+			if err != nil {
+				return "", nil, err
+			}
+			controls = append(controls, prev)
+		}
+
+		// The page number refreshes the page it is on.
+		current, err := pager.button(fmt.Sprintf("%d/%d", page+1, pages), query, page)
+		if err != nil {
+			return "", nil, err
+		}
+		controls = append(controls, current)
+
+		if page < pages-1 {
+			next, err := pager.button("›", query, page+1)
+			if err != nil {
+				return "", nil, err
+			}
+			controls = append(controls, next)
+		}
+
+		keyboard.InlineRow(controls...)(markup)
+	}
+
+	return pager.Text(query, page, pages), markup, nil
+}
+
+func (pager *Pager) button(text, query string, page int) (tgbotapi.InlineKeyboardButton, error) {
+	return pager.bot.CallbackButton(text, pager.prefix, pagerState{
+		Page:  page,
+		Query: query,
+	})
+}
@@ -0,0 +1,545 @@
+package telegram
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	// Registered so that prepared photos can be decoded in these formats.
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// The limits Telegram puts on photos. Anything larger is sent as a document.
+const (
+	maxPhotoSize       = 10 << 20
+	maxPhotoDimensions = 10000 // width + height
+	maxPhotoRatio      = 20
+)
+
+// maxDecodePixels is the most pixels an image can have to be decoded for
+// preparing, which keeps small files with huge dimensions from using up all
+// the memory. Larger images are sent as documents as they are.
+const maxDecodePixels = 50000000
+
+// photoExtensions are the types Telegram accepts as photos, and the extension
+// to upload each with.
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// photoRejections are the errors Telegram gives for files it won't take as a
+// photo, but would as a document.
+var photoRejections = []string{
+	"PHOTO_INVALID_DIMENSIONS",
+	"PHOTO_SAVE_FILE_INVALID",
+	"IMAGE_PROCESS_FAILED",
+}
+
+// photoUpload is an image ready to upload.
+type photoUpload struct {
+	file tgbotapi.InputFile
+
+	// asDocument is set if Telegram won't take the file as a photo.
+	asDocument bool
+
+	// rewind readies the file to be sent again, after Telegram refused it
+	// as a photo. It returns tgbotapi.ErrNotRewindable for files streamed
+	// from readers which can't seek.
+	rewind func() error
+}
+
+// preparePhoto gets an image ready to upload, naming it after its real type,
+// which is found from the start of it. If prepare is set, it is read into
+// memory, scaled down to fit Telegram's limits and has its metadata removed;
+// otherwise it is streamed, with only as much read beforehand as it takes to
+// find its dimensions.
+func preparePhoto(r io.Reader, prepare bool) (photoUpload, error) {
+	seeker, seekable := r.(io.Seeker)
+	var start int64
+	if seekable {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seekable = false
+		}
+	}
+
+	size := readerSize(r, start)
+
+	br := bufio.NewReader(r)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return photoUpload{}, err
+	}
+
+	contentType := http.DetectContentType(head)
+	ext, ok := photoExtensions[contentType]
+
+	if ok && prepare {
+		data, err := ioutil.ReadAll(br)
+		if err != nil {
+			return photoUpload{}, err
+		}
+
+		data, contentType, tooLarge := prepareImage(data, contentType)
+
+		return photoUpload{
+			file:       tgbotapi.FileBytes("photo"+photoExtensions[contentType], data),
+			asDocument: tooLarge || !fitsPhoto(data),
+			rewind:     func() error { return nil },
+		}, nil
+	}
+
+	fits := ok && (size < 0 || size <= maxPhotoSize)
+
+	// The header read to find the dimensions is put back in front of the
+	// rest of the image, unless the reader can go back to the start.
+	var header bytes.Buffer
+	if fits {
+		if cfg, _, err := image.DecodeConfig(io.TeeReader(br, &header)); err == nil {
+			fits = fitsConfig(cfg)
+		}
+	}
+
+	name := "photo" + ext
+	if !ok {
+		name = "file" + extensionFor(contentType)
+	}
+
+	if seekable {
+		rewind := func() error {
+			_, err := seeker.Seek(start, io.SeekStart)
+			return err
+		}
+		if err := rewind(); err != nil {
+			return photoUpload{}, err
+		}
+
+		return photoUpload{
+			file:       tgbotapi.FileReader(name, r),
+			asDocument: !fits,
+			rewind:     rewind,
+		}, nil
+	}
+
+	return photoUpload{
+		file:       tgbotapi.FileReader(name, io.MultiReader(&header, br)),
+		asDocument: !fits,
+		rewind:     func() error { return tgbotapi.ErrNotRewindable },
+	}, nil
+}
+
+// readerSize returns how many bytes are left to read from r, which is at
+// start if it is seekable, or -1 if that can't be told without reading it.
+func readerSize(r io.Reader, start int64) int64 {
+	if l, ok := r.(interface {
+		Len() int
+	}); ok {
+		return int64(l.Len())
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return -1
+		}
+
+		return end - start
+	}
+
+	return -1
+}
+
+// extensionFor returns the extension for files of contentType, if it has one.
+func extensionFor(contentType string) string {
+	mediaType := strings.SplitN(contentType, ";", 2)[0]
+
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+
+	return exts[0]
+}
+
+// fitsPhoto returns true if Telegram will take data as a photo. Images in
+// formats which can't be decoded are assumed to fit if they are small enough.
+func fitsPhoto(data []byte) bool {
+	if len(data) > maxPhotoSize {
+		return false
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return true
+	}
+
+	return fitsConfig(cfg)
+}
+
+// fitsConfig returns true if an image's dimensions are within Telegram's
+// limits for photos.
+func fitsConfig(cfg image.Config) bool {
+	return fitsDimensions(cfg.Width, cfg.Height) && cfg.Width <= cfg.Height*maxPhotoRatio &&
+		cfg.Height <= cfg.Width*maxPhotoRatio
+}
+
+func fitsDimensions(width, height int) bool {
+	return width+height <= maxPhotoDimensions
+}
+
+// prepareImage strips the metadata from an image, and scales and re-encodes
+// it if it is too large to be a photo. Images which can't be decoded are
+// returned as they are, and those with too many pixels to decode are only
+// stripped, and reported as too large.
+func prepareImage(data []byte, contentType string) ([]byte, string, bool) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return data, contentType, false
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	// The metadata is removed before anything else, so it is gone however
+	// the image ends up being sent. Images which are too large to decode
+	// lose their EXIF orientation along with it.
+	stripped, err := stripMetadata(data, format)
+	if err == nil {
+		data = stripped
+	}
+
+	// Photos which fit and don't need turning the right way up are sent as
+	// they are, rather than losing quality to re-encoding.
+	if err == nil && fitsDimensions(cfg.Width, cfg.Height) && len(data) <= maxPhotoSize && orientation == 1 {
+		return data, contentType, false
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > maxDecodePixels {
+		return data, contentType, true
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, contentType, false
+	}
+
+	encoded, err := encodePhoto(orient(toRGBA(img), orientation))
+	if err != nil {
+		return data, contentType, false
+	}
+
+	return encoded, "image/jpeg", false
+}
+
+// encodePhoto encodes img as a JPEG, scaling it and lowering the quality
+// until it fits Telegram's limits.
+func encodePhoto(img *image.RGBA) ([]byte, error) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if !fitsDimensions(width, height) {
+		width = width * maxPhotoDimensions / (width + height)
+		height = maxPhotoDimensions - width
+		img = scale(img, width, height)
+	}
+
+	var buf bytes.Buffer
+
+	for {
+		for _, quality := range []int{90, 75, 60} {
+			buf.Reset()
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, err
+			}
+
+			if buf.Len() <= maxPhotoSize {
+				return buf.Bytes(), nil
+			}
+		}
+
+		if width <= 1 || height <= 1 {
+			return buf.Bytes(), nil
+		}
+
+		width, height = width*3/4, height*3/4
+		img = scale(img, width, height)
+	}
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	return rgba
+}
+
+// scale resizes src to width by height, averaging the pixels which make up
+// each new one.
+func scale(src *image.RGBA, width, height int) *image.RGBA {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, (y+1)*srcHeight/height
+		if y1 == y0 {
+			y1++
+		}
+
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, (x+1)*srcWidth/width
+			if x1 == x0 {
+				x1++
+			}
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i, v := range row {
+					sum[i%4] += int(v)
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+// orient turns img the right way up according to its EXIF orientation.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dstWidth, dstHeight := w, h
+	if orientation >= 5 {
+		dstWidth, dstHeight = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+
+			switch orientation {
+			case 2: // Needs flipping horizontally.
+				sx, sy = w-1-x, y
+			case 3: // Needs turning 180°.
+				sx, sy = w-1-x, h-1-y
+			case 4: // Needs flipping vertically.
+				sx, sy = x, h-1-y
+			case 5: // Needs transposing.
+				sx, sy = y, x
+			case 6: // Needs turning 90° clockwise.
+				sx, sy = y, h-1-x
+			case 7: // Needs transversing.
+				sx, sy = w-1-y, h-1-x
+			case 8: // Needs turning 90° anticlockwise.
+				sx, sy = w-1-y, x
+			}
+
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], img.Pix[img.PixOffset(sx, sy):img.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegSegments calls fn with each marker and segment before the image data
+// of a JPEG, and returns the offset the image data starts at.
+func jpegSegments(data []byte, fn func(marker byte, segment []byte)) (int, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, errors.New("not a JPEG")
+	}
+
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 0, errors.New("malformed JPEG")
+		}
+
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 0, errors.New("malformed JPEG")
+		}
+
+		fn(marker, data[i:i+2+length])
+
+		// The image data follows the start of scan header.
+		if marker == 0xDA {
+			return i + 2 + length, nil
+		}
+
+		i += 2 + length
+	}
+
+	return 0, errors.New("truncated JPEG")
+}
+
+// stripJPEG removes the EXIF, XMP and IPTC metadata and comments from a
+// JPEG, keeping its colour profile.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := []byte{0xFF, 0xD8}
+
+	start, err := jpegSegments(data, func(marker byte, segment []byte) {
+		switch marker {
+		case 0xE1, 0xED, 0xFE: // APP1, APP13 and COM.
+			return
+		}
+
+		out = append(out, segment...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, data[start:]...), nil
+}
+
+// stripMetadata removes the metadata from an image in format, as named by
+// the image package. Formats it doesn't know are returned as they are.
+func stripMetadata(data []byte, format string) ([]byte, error) {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	}
+
+	return data, nil
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG, or 1 if it has
+// none.
+func jpegOrientation(data []byte) int {
+	orientation := 1
+
+	jpegSegments(data, func(marker byte, segment []byte) {
+		if marker != 0xE1 || len(segment) < 10 || string(segment[4:10]) != "Exif\x00\x00" {
+			return
+		}
+
+		tiff := segment[10:]
+		if len(tiff) < 8 {
+			return
+		}
+
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return
+		}
+
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd+2 > len(tiff) {
+			return
+		}
+
+		count := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < count; i++ {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				return
+			}
+
+			if order.Uint16(tiff[entry:]) == 0x0112 {
+				orientation = int(order.Uint16(tiff[entry+8:]))
+				return
+			}
+		}
+	})
+
+	return orientation
+}
+
+// stripPNG removes the text, time and EXIF chunks from a PNG.
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, errors.New("not a PNG")
+	}
+
+	out := []byte(signature)
+
+	for i := len(signature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, errors.New("truncated PNG")
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errors.New("truncated PNG")
+		}
+
+		chunk := data[i:end]
+		if crc32.ChecksumIEEE(chunk[4:8+length]) != binary.BigEndian.Uint32(chunk[8+length:]) {
+			return nil, errors.New("corrupt PNG")
+		}
+
+		switch string(chunk[4:8]) {
+		case "tEXt", "zTXt", "iTXt", "tIME", "eXIf":
+		default:
+			out = append(out, chunk...)
+		}
+
+		i = end
+	}
+
+	return out, nil
+}
+
+// isPhotoRejection returns true if err is Telegram refusing a file as a photo.
+func isPhotoRejection(err error) bool {
+	for _, rejection := range photoRejections {
+		if strings.Contains(err.Error(), rejection) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,350 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+var (
+	red  = color.RGBA{255, 0, 0, 255}
+	blue = color.RGBA{0, 0, 255, 255}
+)
+
+// testJPEG encodes a width by height JPEG, with an EXIF orientation if it
+// isn't 0.
+func testJPEG(t *testing.T, width, height, orientation int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if orientation == 0 {
+		return data
+	}
+
+	// A little-endian TIFF header and an IFD holding only the orientation.
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint16(tiff[18:], uint16(orientation))
+
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(exif)+2))
+	app1 = append(app1, exif...)
+
+	return append(append([]byte{0xFF, 0xD8}, app1...), data[2:]...)
+}
+
+// pngChunk encodes a PNG chunk, with its checksum.
+func pngChunk(kind string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], kind)
+	chunk = append(chunk, data...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// pngHeader returns the start of a PNG claiming to be width by height,
+// which is all image.DecodeConfig needs.
+func pngHeader(width, height int) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr, uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(height))
+	ihdr[8], ihdr[9] = 8, 6
+
+	return append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("IHDR", ihdr)...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	if o := jpegOrientation(testJPEG(t, 4, 2, 0)); o != 1 {
+		t.Errorf("untagged JPEG has orientation %d, want 1", o)
+	}
+
+	for _, want := range []int{1, 3, 6, 8} {
+		if o := jpegOrientation(testJPEG(t, 4, 2, want)); o != want {
+			t.Errorf("got orientation %d, want %d", o, want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A red pixel to the left of a blue one.
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	for _, test := range []struct {
+		orientation   int
+		width, height int
+		first         color.RGBA
+	}{
+		{1, 2, 1, red},
+		{2, 2, 1, blue},
+		{3, 2, 1, blue},
+		{6, 1, 2, red},
+		{8, 1, 2, blue},
+	} {
+		dst := orient(src, test.orientation)
+
+		if w, h := dst.Bounds().Dx(), dst.Bounds().Dy(); w != test.width || h != test.height {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", test.orientation, w, h, test.width, test.height)
+			continue
+		}
+		if c := dst.RGBAAt(0, 0); c != test.first {
+			t.Errorf("orientation %d: top left is %v, want %v", test.orientation, c, test.first)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	// Black on the left half, white on the right.
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x < 2 {
+				src.Set(x, y, color.Black)
+			} else {
+				src.Set(x, y, color.White)
+			}
+		}
+	}
+
+	dst := scale(src, 2, 2)
+	if w, h := dst.Bounds().Dx(), dst.Bounds().Dy(); w != 2 || h != 2 {
+		t.Fatalf("got %dx%d, want 2x2", w, h)
+	}
+	if c := dst.RGBAAt(0, 1); c.R != 0 {
+		t.Errorf("left is %v, want black", c)
+	}
+	if c := dst.RGBAAt(1, 1); c.R != 255 {
+		t.Errorf("right is %v, want white", c)
+	}
+
+	if c := scale(src, 1, 1).RGBAAt(0, 0); c.R != 127 || c.A != 255 {
+		t.Errorf("average is %v, want grey", c)
+	}
+}
+
+func TestStripJPEG(t *testing.T) {
+	stripped, err := stripJPEG(testJPEG(t, 4, 2, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o := jpegOrientation(stripped); o != 1 {
+		t.Errorf("stripped JPEG still has orientation %d", o)
+	}
+	if bytes.Contains(stripped, []byte("Exif")) {
+		t.Error("stripped JPEG still has EXIF")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped JPEG doesn't decode: %v", err)
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Put a comment after the header.
+	data := buf.Bytes()
+	end := 8 + 12 + 13
+	data = append(append(append([]byte{}, data[:end]...), pngChunk("tEXt", []byte("Comment\x00secret"))...), data[end:]...)
+
+	stripped, err := stripPNG(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(stripped, []byte("secret")) {
+		t.Error("stripped PNG still has its comment")
+	}
+	if _, err := png.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped PNG doesn't decode: %v", err)
+	}
+
+	if _, err := stripPNG(data[:len(data)-2]); err == nil {
+		t.Error("truncated PNG was stripped")
+	}
+}
+
+func TestPrepareImageOrients(t *testing.T) {
+	data, contentType, tooLarge := prepareImage(testJPEG(t, 4, 2, 6), "image/jpeg")
+	if tooLarge || contentType != "image/jpeg" {
+		t.Fatalf("got %s, too large %v", contentType, tooLarge)
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 2 || cfg.Height != 4 {
+		t.Errorf("got %dx%d, want 2x4", cfg.Width, cfg.Height)
+	}
+}
+
+// hugeJPEG returns a JPEG with an EXIF orientation, whose header claims it
+// is too large to decode.
+func hugeJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	data := testJPEG(t, 8, 8, 6)
+
+	var sof int
+	if _, err := jpegSegments(data, func(marker byte, segment []byte) {
+		if marker == 0xC0 {
+			sof = bytes.Index(data, segment)
+		}
+	}); err != nil || sof == 0 {
+		t.Fatalf("no start of frame: %v", err)
+	}
+
+	binary.BigEndian.PutUint16(data[sof+5:], 9000)
+	binary.BigEndian.PutUint16(data[sof+7:], 9000)
+
+	return data
+}
+
+func TestPrepareImagePixelCap(t *testing.T) {
+	// Small enough to read, far too many pixels to decode.
+	for name, data := range map[string][]byte{
+		"jpeg": hugeJPEG(t),
+		"png":  append(pngHeader(10000, 10000), pngChunk("tEXt", []byte("GPS\x0051.5,-0.1"))...),
+	} {
+		got, _, tooLarge := prepareImage(data, "image/"+name)
+		if !tooLarge {
+			t.Errorf("%s: image over the pixel cap wasn't reported as too large", name)
+		}
+		if bytes.Contains(got, []byte("Exif")) || bytes.Contains(got, []byte("GPS")) {
+			t.Errorf("%s: image over the pixel cap kept its metadata", name)
+		}
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(got)); err != nil || cfg.Width != 9000 && cfg.Width != 10000 {
+			t.Errorf("%s: stripped image doesn't have its header: %v", name, err)
+		}
+	}
+}
+
+// uploadRecorder answers upload requests, and records the endpoint and body
+// of the last one.
+type uploadRecorder struct {
+	endpoint string
+	body     []byte
+}
+
+func (rec *uploadRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec.endpoint = path.Base(req.URL.Path)
+	rec.body, _ = ioutil.ReadAll(req.Body)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"ok":true,"result":{"message_id":2,"chat":{"id":1},"document":{"file_id":"doc"}}}`)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestPreparePhotoOversized(t *testing.T) {
+	photo, err := preparePhoto(bytes.NewReader(pngHeader(9000, 9000)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !photo.asDocument {
+		t.Error("oversized photo wasn't sent as a document")
+	}
+
+	rec := &uploadRecorder{}
+	transport := http.DefaultTransport
+	http.DefaultTransport = rec
+	defer func() {
+		http.DefaultTransport = transport
+	}()
+
+	msg := &Message{
+		Message: tgbotapi.Message{MessageID: 1, Chat: tgbotapi.UserOrGroupChat{ID: 1}},
+		bot:     &Bot{api: &tgbotapi.BotAPI{Token: "123:secret"}},
+		dir:     incoming,
+	}
+	if _, err := msg.UploadPhoto(bytes.NewReader(hugeJPEG(t)), "", PreparePhoto).Upload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.endpoint != "sendDocument" {
+		t.Errorf("sent with %s, want sendDocument", rec.endpoint)
+	}
+	if bytes.Contains(rec.body, []byte("Exif")) {
+		t.Error("oversized photo was sent with its EXIF")
+	}
+}
+
+// countingReader counts the bytes read from it, and isn't seekable.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+
+	return n, err
+}
+
+func TestPreparePhotoStreams(t *testing.T) {
+	data := append(pngHeader(100, 100), make([]byte, 1<<20)...)
+
+	r := &countingReader{r: bytes.NewReader(data)}
+	photo, err := preparePhoto(r, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if photo.asDocument {
+		t.Error("photo was sent as a document")
+	}
+	if r.n >= 64<<10 {
+		t.Errorf("read %d bytes before sending", r.n)
+	}
+	if err := photo.rewind(); !errors.Is(err, tgbotapi.ErrNotRewindable) {
+		t.Errorf("rewinding a stream returned %v", err)
+	}
+}
+
+func TestPreparePhotoRewinds(t *testing.T) {
+	data := append(pngHeader(100, 100), make([]byte, 1<<20)...)
+
+	r := bytes.NewReader(data)
+	photo, err := preparePhoto(r, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Len() != len(data) {
+		t.Errorf("reader left %d bytes in, want it back at the start", len(data)-r.Len())
+	}
+
+	r.Seek(100, io.SeekStart)
+	if err := photo.rewind(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != len(data) {
+		t.Error("rewind didn't go back to the start")
+	}
+}
@@ -0,0 +1,222 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// progressInterval is the least time between edits of a progress message,
+// which keeps well inside Telegram's limits on edits, even in groups.
+var progressInterval = 3 * time.Second
+
+// progressBarWidth is how many characters wide progress bars are.
+const progressBarWidth = 10
+
+// Progress is a status message showing how a long-running command is getting
+// on, created with Message.Progress. It is edited as progress is reported,
+// at most every few seconds, with the latest progress.
+//
+// It is safe for concurrent use. Once Done or Fail has been called, further
+// progress is ignored.
+type Progress struct {
+	status *Message
+
+	lock     sync.Mutex
+	title    string
+	stage    string
+	fraction float64
+	finished bool
+	last     time.Time
+	timer    *time.Timer
+
+	// editing is held while the status message is edited, so edits happen
+	// one at a time and in order.
+	editing sync.Mutex
+}
+
+// Progress replies to this message with a status message headed by title,
+// which the returned Progress keeps up to date.
+func (msg Message) Progress(title string) (*Progress, error) {
+	progress := &Progress{
+		title:    title,
+		fraction: -1,
+	}
+
+	status := msg.ReplyWith("%s", progress.render())
+	if status == nil {
+		status = msg.bot.Message(msg.chatRef(), "%s", progress.render())
+	}
+
+	if err := status.Send(); err != nil {
+		return nil, err
+	}
+
+	progress.status = status
+	progress.last = time.Now()
+
+	return progress, nil
+}
+
+// Update reports how far along the command is, from 0 to 1, and what it is
+// doing now. A negative fraction hides the progress bar.
+func (progress *Progress) Update(fraction float64, stage string) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	progress.fraction = fraction
+	progress.stage = stage
+	progress.schedule()
+}
+
+// Stage reports what the command is doing now, keeping the progress bar as
+// it is.
+func (progress *Progress) Stage(stage string) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	progress.stage = stage
+	progress.schedule()
+}
+
+// Upload returns a ProgressFunc which reports the progress of uploading a
+// file, such as with the OnProgress option, as stage.
+func (progress *Progress) Upload(stage string) tgbotapi.ProgressFunc {
+	return func(sent, total int64) {
+		if total <= 0 {
+			progress.Update(-1, stage)
+			return
+		}
+
+		progress.Update(float64(sent)/float64(total), stage)
+	}
+}
+
+// Done marks the command as finished, replacing the progress with the
+// printf-formatted result.
+func (progress *Progress) Done(f string, args ...interface{}) error {
+	return progress.finish("✅ " + progress.title + "\n" + fmt.Sprintf(f, args...))
+}
+
+// Fail marks the command as failed with err.
+func (progress *Progress) Fail(err error) error {
+	return progress.finish("❌ " + progress.title + "\n" + err.Error())
+}
+
+// finish stops any pending edit, and edits the status message to text.
+func (progress *Progress) finish(text string) error {
+	progress.lock.Lock()
+	if progress.finished {
+		progress.lock.Unlock()
+		return errors.New("telegram: progress has already finished")
+	}
+
+	progress.finished = true
+	if progress.timer != nil {
+		progress.timer.Stop()
+		progress.timer = nil
+	}
+	progress.lock.Unlock()
+
+	progress.editing.Lock()
+	defer progress.editing.Unlock()
+
+	// The wait is worked out once any edit in progress is over, so it counts
+	// from when that edit was made.
+	progress.lock.Lock()
+	wait := time.Until(progress.last.Add(progressInterval))
+	progress.lock.Unlock()
+
+	// The final edit can't be dropped, so waits its turn rather than
+	// risking being refused.
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	err := progress.status.Edit("%s", text)
+	if delay := retryAfter(err); delay > 0 {
+		time.Sleep(delay)
+		err = progress.status.Edit("%s", text)
+	}
+
+	return err
+}
+
+// schedule arranges for the status message to be edited with the latest
+// progress once enough time has passed since the last edit. The lock must be
+// held.
+func (progress *Progress) schedule() {
+	if progress.finished || progress.timer != nil {
+		return
+	}
+
+	wait := time.Until(progress.last.Add(progressInterval))
+	if wait < 0 {
+		wait = 0
+	}
+
+	progress.timer = time.AfterFunc(wait, progress.flush)
+}
+
+// flush edits the status message with the latest progress.
+func (progress *Progress) flush() {
+	progress.editing.Lock()
+	defer progress.editing.Unlock()
+
+	progress.lock.Lock()
+	if progress.finished {
+		progress.lock.Unlock()
+		return
+	}
+
+	progress.timer = nil
+	text := progress.render()
+	progress.lock.Unlock()
+
+	err := progress.status.Edit("%s", text)
+
+	progress.lock.Lock()
+	progress.last = time.Now()
+	if delay := retryAfter(err); delay > 0 {
+		progress.last = progress.last.Add(delay)
+	}
+	progress.lock.Unlock()
+}
+
+// render returns the text of the status message. The lock must be held.
+func (progress *Progress) render() string {
+	lines := []string{"⏳ " + progress.title}
+
+	if progress.fraction >= 0 {
+		filled := int(progress.fraction * progressBarWidth)
+		lines = append(lines, fmt.Sprintf("%s%s %d%%",
+			strings.Repeat("█", filled),
+			strings.Repeat("░", progressBarWidth-filled),
+			int(progress.fraction*100)))
+	}
+
+	if progress.stage != "" {
+		lines = append(lines, progress.stage)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// retryAfter returns how long Telegram asked to wait before trying again, if
+// err is it refusing a request for being too soon.
+func retryAfter(err error) time.Duration {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Parameters == nil {
+		return 0
+	}
+
+	return time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+}
@@ -0,0 +1,130 @@
+package telegram
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// editRecorder answers editMessageText requests, taking delay over each, and
+// records when they were made and the text they set.
+type editRecorder struct {
+	delay time.Duration
+
+	lock  sync.Mutex
+	times []time.Time
+	texts []string
+
+	// started has a value sent to it as each edit starts, if set.
+	started chan struct{}
+}
+
+func (rec *editRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := ioutil.ReadAll(req.Body)
+	v, _ := url.ParseQuery(string(body))
+
+	rec.lock.Lock()
+	rec.times = append(rec.times, time.Now())
+	rec.texts = append(rec.texts, v.Get("text"))
+	rec.lock.Unlock()
+
+	if rec.started != nil {
+		rec.started <- struct{}{}
+	}
+	time.Sleep(rec.delay)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"ok":true,"result":true}`)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func (rec *editRecorder) edits() ([]time.Time, []string) {
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+
+	return append([]time.Time{}, rec.times...), append([]string{}, rec.texts...)
+}
+
+// newTestProgress returns a Progress whose status message was last edited
+// at last, with edits going to rec.
+func newTestProgress(t *testing.T, rec *editRecorder, last time.Time) *Progress {
+	interval := progressInterval
+	progressInterval = 100 * time.Millisecond
+
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = rec
+	t.Cleanup(func() {
+		progressInterval = interval
+		http.DefaultClient.Transport = transport
+	})
+
+	return &Progress{
+		status: &Message{
+			Message: tgbotapi.Message{MessageID: 1, Chat: tgbotapi.UserOrGroupChat{ID: 1}},
+			bot:     &Bot{api: &tgbotapi.BotAPI{Token: "123:secret"}},
+			dir:     outgoing,
+		},
+		title:    "Working",
+		fraction: -1,
+		last:     last,
+	}
+}
+
+func TestProgressThrottles(t *testing.T) {
+	rec := &editRecorder{}
+	start := time.Now()
+	progress := newTestProgress(t, rec, start)
+
+	for i := 0; i <= 10; i++ {
+		progress.Update(float64(i)/10, "")
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(3 * progressInterval)
+
+	times, texts := rec.edits()
+	if len(times) != 1 {
+		t.Fatalf("edited %d times, want once", len(times))
+	}
+	if gap := times[0].Sub(start); gap < progressInterval {
+		t.Errorf("edited %v after the last edit, want at least %v", gap, progressInterval)
+	}
+	if !strings.Contains(texts[0], "100%") {
+		t.Errorf("edited to %q, want the latest progress", texts[0])
+	}
+}
+
+func TestProgressDoneWaitsForEdit(t *testing.T) {
+	rec := &editRecorder{
+		delay:   50 * time.Millisecond,
+		started: make(chan struct{}, 2),
+	}
+	progress := newTestProgress(t, rec, time.Now().Add(-time.Hour))
+
+	progress.Update(0.5, "halfway")
+	<-rec.started
+
+	// Finishing while the update is being edited in waits for the interval
+	// after that edit, not the one before it.
+	if err := progress.Done("all done"); err != nil {
+		t.Fatal(err)
+	}
+
+	times, texts := rec.edits()
+	if len(times) != 2 {
+		t.Fatalf("edited %d times, want twice", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < progressInterval {
+		t.Errorf("edits were %v apart, want at least %v", gap, progressInterval)
+	}
+	if !strings.Contains(texts[1], "all done") {
+		t.Errorf("finished with %q", texts[1])
+	}
+}
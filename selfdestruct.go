@@ -0,0 +1,277 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+// deletionRetryDelay is how long to wait before trying a deletion again after
+// it failed to reach Telegram.
+const deletionRetryDelay = time.Minute
+
+// cantDeleteExpiry is how long the bot stops trying to delete other people's
+// messages in a chat after being refused, in case it is given the right to.
+const cantDeleteExpiry = time.Hour
+
+// ScheduledDeletion is a message to be deleted once At has passed.
+type ScheduledDeletion struct {
+	ChatID    int64     `json:"chat_id"`
+	MessageID int32     `json:"message_id"`
+	At        time.Time `json:"at"`
+
+	// Sender is true if the bot sent the message, rather than it being
+	// the command the bot replied to.
+	Sender bool `json:"sender"`
+}
+
+// DeletionStore keeps scheduled deletions, so that they still happen after a
+// restart. Implement it to keep them somewhere other than a JSON file.
+type DeletionStore interface {
+	Load() ([]ScheduledDeletion, error)
+	Save([]ScheduledDeletion) error
+}
+
+// JSONDeletionStore is a DeletionStore kept in a JSON file.
+type JSONDeletionStore struct {
+	Path string
+}
+
+// Load reads the scheduled deletions, of which there are none if the file
+// doesn't exist.
+func (store JSONDeletionStore) Load() ([]ScheduledDeletion, error) {
+	data, err := ioutil.ReadFile(store.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var deletions []ScheduledDeletion
+	if err := json.Unmarshal(data, &deletions); err != nil {
+		return nil, err
+	}
+
+	return deletions, nil
+}
+
+// Save replaces the scheduled deletions, only once the new file is complete.
+func (store JSONDeletionStore) Save(deletions []ScheduledDeletion) error {
+	data, err := json.Marshal(deletions)
+	if err != nil {
+		return err
+	}
+
+	tmp := store.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, store.Path)
+}
+
+// SelfDestruct deletes the message ttl after it is sent, and if
+// withCommand is set, the command it replies to as well. Set on an incoming
+// message, it applies to every reply to it instead, including media, albums,
+// LiveReply and Progress, whose messages are deleted ttl after they are
+// first sent. Bots can only delete their own messages for 48 hours after
+// sending them, and other people's only in groups where they have the right
+// to; when they can't, the message is left alone.
+func (msg *Message) SelfDestruct(ttl time.Duration, withCommand bool) *Message {
+	msg.ttl = ttl
+	msg.deleteCommand = withCommand
+
+	return msg
+}
+
+// scheduleDeletion schedules the deletion of sent, which has just been sent
+// from msg, if it should self-destruct.
+func (msg Message) scheduleDeletion(sent tgbotapi.Message) {
+	if msg.ttl <= 0 || msg.bot.deletions == nil {
+		return
+	}
+
+	at := time.Now().Add(msg.ttl)
+
+	msg.bot.deletions.schedule(ScheduledDeletion{
+		ChatID:    sent.Chat.ID,
+		MessageID: sent.MessageID,
+		At:        at,
+		Sender:    true,
+	})
+
+	if command := msg.replyOptions().ReplyToMessageID; msg.deleteCommand && command != 0 {
+		msg.bot.deletions.schedule(ScheduledDeletion{
+			ChatID:    sent.Chat.ID,
+			MessageID: command,
+			At:        at,
+		})
+	}
+}
+
+// SetDeletionStore keeps the bot's scheduled deletions in store, and
+// schedules those left in it from before. Without one, scheduled deletions
+// are lost when the bot stops.
+func (bot *Bot) SetDeletionStore(store DeletionStore) error {
+	if bot.deletions == nil {
+		bot.deletions = newDeletionQueue(bot.api)
+	}
+
+	return bot.deletions.setStore(store)
+}
+
+// deletionQueue deletes messages when their time comes.
+type deletionQueue struct {
+	api *tgbotapi.BotAPI
+
+	lock    sync.Mutex
+	store   DeletionStore
+	pending map[string]*pendingDeletion
+
+	// cantDelete are the chats where the bot has been refused deleting
+	// other people's messages, and when, so it stops trying for a while.
+	cantDelete map[int64]time.Time
+}
+
+type pendingDeletion struct {
+	ScheduledDeletion
+
+	timer *time.Timer
+}
+
+func newDeletionQueue(api *tgbotapi.BotAPI) *deletionQueue {
+	return &deletionQueue{
+		api: api,
+
+		pending:    make(map[string]*pendingDeletion),
+		cantDelete: make(map[int64]time.Time),
+	}
+}
+
+func deletionKey(deletion ScheduledDeletion) string {
+	return strconv.FormatInt(deletion.ChatID, 10) + ":" + strconv.Itoa(int(deletion.MessageID))
+}
+
+func (queue *deletionQueue) setStore(store DeletionStore) error {
+	deletions, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	queue.lock.Lock()
+	queue.store = store
+	for _, deletion := range deletions {
+		queue.add(deletion)
+	}
+	queue.save()
+	queue.lock.Unlock()
+
+	return nil
+}
+
+func (queue *deletionQueue) schedule(deletion ScheduledDeletion) {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	if refused, ok := queue.cantDelete[deletion.ChatID]; ok && !deletion.Sender {
+		if time.Since(refused) < cantDeleteExpiry {
+			return
+		}
+
+		delete(queue.cantDelete, deletion.ChatID)
+	}
+
+	queue.add(deletion)
+	queue.save()
+}
+
+// add sets a timer for a deletion. The lock must be held.
+func (queue *deletionQueue) add(deletion ScheduledDeletion) {
+	key := deletionKey(deletion)
+	if old, ok := queue.pending[key]; ok {
+		old.timer.Stop()
+	}
+
+	pending := &pendingDeletion{
+		ScheduledDeletion: deletion,
+	}
+	pending.timer = time.AfterFunc(time.Until(deletion.At), func() {
+		queue.run(key, pending)
+	})
+
+	queue.pending[key] = pending
+}
+
+// save writes the pending deletions to the store. The lock must be held.
+func (queue *deletionQueue) save() {
+	if queue.store == nil {
+		return
+	}
+
+	deletions := make([]ScheduledDeletion, 0, len(queue.pending))
+	for _, pending := range queue.pending {
+		deletions = append(deletions, pending.ScheduledDeletion)
+	}
+
+	if err := queue.store.Save(deletions); err != nil {
+		log.Printf("telegram: couldn't save scheduled deletions: %v", err)
+	}
+}
+
+// run deletes a message whose time has come. Messages which Telegram won't
+// let the bot delete are given up on, while deletions which didn't reach
+// Telegram are tried again later.
+func (queue *deletionQueue) run(key string, pending *pendingDeletion) {
+	err := queue.api.DeleteMessage(tgbotapi.NewDeleteMessage(tgbotapi.ChatID(pending.ChatID), pending.MessageID))
+
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+
+	if queue.pending[key] != pending {
+		return
+	}
+
+	var apiErr *tgbotapi.Error
+	switch {
+	case err == nil:
+		if !pending.Sender {
+			delete(queue.cantDelete, pending.ChatID)
+		}
+	case retryAfter(err) > 0:
+		pending.At = time.Now().Add(retryAfter(err))
+		queue.add(pending.ScheduledDeletion)
+		queue.save()
+		return
+	case errors.As(err, &apiErr):
+		if !pending.Sender && lacksDeleteRights(apiErr) {
+			queue.cantDelete[pending.ChatID] = time.Now()
+		}
+
+		if queue.api.Debug {
+			log.Printf("telegram: can't delete message %d in %d: %v", pending.MessageID, pending.ChatID, err)
+		}
+	default:
+		pending.At = time.Now().Add(deletionRetryDelay)
+		queue.add(pending.ScheduledDeletion)
+		queue.save()
+		return
+	}
+
+	delete(queue.pending, key)
+	queue.save()
+}
+
+// lacksDeleteRights returns true if err is Telegram refusing to delete a
+// message because the bot isn't allowed to, rather than because it is gone.
+func lacksDeleteRights(err *tgbotapi.Error) bool {
+	return strings.Contains(err.Description, "can't be deleted") ||
+		strings.Contains(err.Description, "not enough rights")
+}
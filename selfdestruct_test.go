@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+)
+
+func TestSelfDestructReplies(t *testing.T) {
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &uploadRecorder{}
+	defer func() {
+		http.DefaultClient.Transport = transport
+	}()
+
+	api := &tgbotapi.BotAPI{Token: "123:secret"}
+	bot := &Bot{api: api, deletions: newDeletionQueue(api)}
+
+	msg := &Message{
+		Message: tgbotapi.Message{MessageID: 1, Chat: tgbotapi.UserOrGroupChat{ID: 1}},
+		bot:     bot,
+		dir:     incoming,
+	}
+	if err := msg.SelfDestruct(time.Hour, true).DocumentReply("doc", "").Send(); err != nil {
+		t.Fatal(err)
+	}
+
+	bot.deletions.lock.Lock()
+	defer bot.deletions.lock.Unlock()
+
+	for _, key := range []string{"1:1", "1:2"} {
+		pending, ok := bot.deletions.pending[key]
+		if !ok {
+			t.Errorf("message %s wasn't scheduled for deletion", key)
+			continue
+		}
+
+		pending.timer.Stop()
+	}
+}
+
+func TestCantDeleteExpires(t *testing.T) {
+	queue := newDeletionQueue(&tgbotapi.BotAPI{Token: "123:secret"})
+	command := ScheduledDeletion{ChatID: 1, MessageID: 1, At: time.Now().Add(time.Hour)}
+
+	queue.cantDelete[1] = time.Now()
+	queue.schedule(command)
+	if _, ok := queue.pending["1:1"]; ok {
+		t.Error("command was scheduled for deletion in a chat where the bot was just refused")
+	}
+
+	queue.cantDelete[1] = time.Now().Add(-2 * cantDeleteExpiry)
+	queue.schedule(command)
+	pending, ok := queue.pending["1:1"]
+	if !ok {
+		t.Fatal("command wasn't scheduled for deletion once the refusal expired")
+	}
+	pending.timer.Stop()
+
+	if _, ok := queue.cantDelete[1]; ok {
+		t.Error("expired refusal was kept")
+	}
+}
@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AmandaCameron/go-telegram/api"
+	"github.com/AmandaCameron/go-telegram/keyboard"
+)
+
+// TimePicker is an inline keyboard with buttons to turn the hour and minute
+// up and down, from which the user picks a time of day on a given date.
+// Create one with Bot.TimePicker.
+type TimePicker struct {
+	bot     *Bot
+	prefix  string
+	handler func(cb *Callback, at time.Time)
+
+	// Text is the text of the message with the time picker.
+	Text string
+
+	// Step is how many minutes the minute buttons move by, which should
+	// divide an hour evenly.
+	Step int
+
+	// Location is the time zone of the times picked.
+	Location *time.Location
+}
+
+// timeState is the payload of a time picker's buttons. Minute is the time
+// shown, in minutes since midnight, and Done is set for the button which
+// picks it.
+type timeState struct {
+	Date   string
+	Minute int
+	Done   bool
+}
+
+// TimePicker creates a time picker which passes the times picked from it to
+// handler, and registers the handler for its buttons. name must be unique
+// among the bot's time pickers.
+func (bot *Bot) TimePicker(name string, handler func(cb *Callback, at time.Time)) *TimePicker {
+	picker := &TimePicker{
+		bot:     bot,
+		prefix:  "time:" + name + ":",
+		handler: handler,
+
+		Text:     "Pick a time:",
+		Step:     15,
+		Location: time.Local,
+	}
+
+	bot.HandleCallbackData(picker.prefix, timeState{}, picker.press)
+	bot.HandleCallback(picker.prefix+noopData, func(*Callback) {})
+
+	return picker
+}
+
+// Show replies to msg with the time picker, for the date of at, starting at
+// its time of day.
+func (picker *TimePicker) Show(msg Message, at time.Time) error {
+	markup, err := picker.render(picker.state(at))
+	if err != nil {
+		return err
+	}
+
+	reply := msg.ReplyWith("%s", picker.Text)
+	if reply == nil {
+		reply = picker.bot.Message(msg.chatRef(), "%s", picker.Text)
+	}
+
+	return reply.WithMarkup(markup).Send()
+}
+
+// Replace edits the message with the button cb is a press of to show the
+// time picker instead, such as once a date has been picked from a Calendar.
+func (picker *TimePicker) Replace(cb *Callback, at time.Time) error {
+	markup, err := picker.render(picker.state(at))
+	if err != nil {
+		return err
+	}
+
+	return cb.show(picker.Text, markup)
+}
+
+// state returns the state showing at, rounded down to a step.
+func (picker *TimePicker) state(at time.Time) timeState {
+	at = at.In(picker.Location)
+	step := picker.step()
+
+	return timeState{
+		Date:   at.Format("2006-01-02"),
+		Minute: at.Hour()*60 + at.Minute()/step*step,
+	}
+}
+
+func (picker *TimePicker) step() int {
+	switch {
+	case picker.Step < 1:
+		return 1
+	case picker.Step > 60:
+		return 60
+	}
+
+	return picker.Step
+}
+
+// press handles the time picker's buttons.
+func (picker *TimePicker) press(cb *Callback, payload interface{}) {
+	state := payload.(*timeState)
+
+	date, err := time.ParseInLocation("2006-01-02", state.Date, picker.Location)
+	if err != nil {
+		cb.Alert("This button has expired.")
+		return
+	}
+
+	if state.Done {
+		picker.handler(cb, time.Date(date.Year(), date.Month(), date.Day(),
+			state.Minute/60, state.Minute%60, 0, 0, picker.Location))
+		return
+	}
+
+	markup, err := picker.render(*state)
+	if err == nil {
+		err = cb.show(picker.Text, markup)
+	}
+
+	if err != nil {
+		cb.fail(err)
+	}
+}
+
+// render returns the keyboard for a time. The hour and minute each wrap
+// around without changing the other.
+func (picker *TimePicker) render(state timeState) (*tgbotapi.InlineKeyboardMarkup, error) {
+	hour, minute, step := state.Minute/60, state.Minute%60, picker.step()
+
+	moves := []struct {
+		text         string
+		hour, minute int
+	}{
+		{"▲", (hour + 1) % 24, minute},
+		{"▲", hour, (minute + step) % 60},
+		{"▼", (hour + 23) % 24, minute},
+		{"▼", hour, (minute - step + 60) % 60},
+	}
+
+	buttons := make([]tgbotapi.InlineKeyboardButton, len(moves))
+	for i, move := range moves {
+		button, err := picker.button(move.text, timeState{
+			Date:   state.Date,
+			Minute: move.hour*60 + move.minute,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		buttons[i] = button
+	}
+
+	done, err := picker.button(fmt.Sprintf("✓ %02d:%02d", hour, minute), timeState{
+		Date:   state.Date,
+		Minute: state.Minute,
+		Done:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keyboard.Inline(
+		keyboard.InlineRow(buttons[0], buttons[1]),
+		keyboard.InlineRow(
+			picker.noop(fmt.Sprintf("%02d", hour)),
+			picker.noop(fmt.Sprintf("%02d", minute))),
+		keyboard.InlineRow(buttons[2], buttons[3]),
+		keyboard.InlineRow(done),
+	), nil
+}
+
+func (picker *TimePicker) button(text string, state timeState) (tgbotapi.InlineKeyboardButton, error) {
+	return picker.bot.CallbackButton(text, picker.prefix, state)
+}
+
+func (picker *TimePicker) noop(text string) tgbotapi.InlineKeyboardButton {
+	return keyboard.Callback(text, picker.prefix+noopData)
+}